@@ -0,0 +1,131 @@
+package handler
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	pb "github.com/micro/blog/audit/proto"
+	"go-micro.dev/v5/errors"
+	"go-micro.dev/v5/store"
+)
+
+const (
+	defaultListLimit = 50
+	maxListLimit     = 200
+)
+
+var auditStore = store.DefaultStore
+
+type Handler struct{}
+
+func New() *Handler {
+	return &Handler{}
+}
+
+// eventKey orders events by when they arrived. Keys are never rewritten,
+// so the log is append only.
+func eventKey(ev *pb.Event, received time.Time) string {
+	return fmt.Sprintf("audit-%020d-%s", received.UnixNano(), ev.Id)
+}
+
+// Record stores an event published on the audit topic. Redelivered events
+// are stored once.
+func (h *Handler) Record(ctx context.Context, ev *pb.Event) error {
+	if ev.Id == "" || ev.Action == "" {
+		log.Printf("Dropping audit event without ID or action: %v", ev)
+		return nil
+	}
+	if rec, err := auditStore.Read("auditid-" + ev.Id); err == nil && len(rec) > 0 {
+		return nil
+	}
+	if ev.CreatedAt == 0 {
+		ev.CreatedAt = time.Now().Unix()
+	}
+	b, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	key := eventKey(ev, time.Now())
+	if err := auditStore.Write(&store.Record{Key: key, Value: b}); err != nil {
+		return err
+	}
+	return auditStore.Write(&store.Record{Key: "auditid-" + ev.Id, Value: []byte(key)})
+}
+
+// matches reports whether an event passes the request's filters
+func matches(ev *pb.Event, req *pb.ListRequest) bool {
+	switch {
+	case req.ActorId != "" && ev.ActorId != req.ActorId:
+		return false
+	case req.Action != "" && !matchAction(ev.Action, req.Action):
+		return false
+	case req.TargetType != "" && ev.TargetType != req.TargetType:
+		return false
+	case req.TargetId != "" && ev.TargetId != req.TargetId:
+		return false
+	case req.Since != 0 && ev.CreatedAt < req.Since:
+		return false
+	case req.Until != 0 && ev.CreatedAt > req.Until:
+		return false
+	}
+	return true
+}
+
+// matchAction compares an action with a filter, where a filter ending in
+// "." matches every action under it, e.g. "auth." matches "auth.login"
+func matchAction(action, filter string) bool {
+	if strings.HasSuffix(filter, ".") {
+		return strings.HasPrefix(action, filter)
+	}
+	return action == filter
+}
+
+func (h *Handler) List(ctx context.Context, req *pb.ListRequest, rsp *pb.ListResponse) error {
+	limit := int(req.Limit)
+	if limit <= 0 {
+		limit = defaultListLimit
+	}
+	if limit > maxListLimit {
+		limit = maxListLimit
+	}
+	// The cursor is the key of the last event returned
+	var after string
+	if req.Cursor != "" {
+		b, err := base64.RawURLEncoding.DecodeString(req.Cursor)
+		if err != nil || !strings.HasPrefix(string(b), "audit-") {
+			return errors.BadRequest("audit.List", "invalid cursor")
+		}
+		after = string(b)
+	}
+
+	// "audit-" doesn't cover the "auditid-" index
+	rec, err := auditStore.Read("audit-", store.ReadPrefix())
+	if err != nil {
+		return errors.InternalServerError("audit.List", "failed to read events")
+	}
+	sort.Slice(rec, func(i, j int) bool { return rec[i].Key > rec[j].Key })
+
+	var last string
+	for _, r := range rec {
+		if after != "" && r.Key >= after {
+			continue
+		}
+		var ev pb.Event
+		if err := json.Unmarshal(r.Value, &ev); err != nil || !matches(&ev, req) {
+			continue
+		}
+		if len(rsp.Events) == limit {
+			rsp.NextCursor = base64.RawURLEncoding.EncodeToString([]byte(last))
+			break
+		}
+		rsp.Events = append(rsp.Events, &ev)
+		last = r.Key
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	pb "github.com/micro/blog/audit/proto"
+	"go-micro.dev/v5/errors"
+	"go-micro.dev/v5/store"
+)
+
+// newTestHandler returns a handler with an empty store
+func newTestHandler(t *testing.T) *Handler {
+	t.Helper()
+	// The file store the service runs with; the memory store's prefix reads
+	// return nothing without a limit
+	auditStore = store.NewFileStore(store.DirOption(t.TempDir()))
+	t.Cleanup(func() { auditStore.Close() })
+	return New()
+}
+
+// record stores events in order
+func record(t *testing.T, h *Handler, events ...*pb.Event) {
+	t.Helper()
+	for _, ev := range events {
+		if err := h.Record(context.Background(), ev); err != nil {
+			t.Fatalf("Record(%s): %v", ev.Id, err)
+		}
+	}
+}
+
+// ids lists the IDs of the events the request returns
+func ids(t *testing.T, h *Handler, req *pb.ListRequest) string {
+	t.Helper()
+	var rsp pb.ListResponse
+	if err := h.List(context.Background(), req, &rsp); err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	var got []string
+	for _, ev := range rsp.Events {
+		got = append(got, ev.Id)
+	}
+	return strings.Join(got, ",")
+}
+
+func TestListFilters(t *testing.T) {
+	h := newTestHandler(t)
+	record(t, h,
+		&pb.Event{Id: "1", Action: "auth.login", ActorId: "jane", TargetType: "user", TargetId: "jane", CreatedAt: 100},
+		&pb.Event{Id: "2", Action: "post.create", ActorId: "jane", TargetType: "post", TargetId: "p1", CreatedAt: 200},
+		&pb.Event{Id: "3", Action: "auth.login_failed", TargetType: "user", TargetId: "john", CreatedAt: 300},
+		&pb.Event{Id: "4", Action: "post.delete", ActorId: "admin", TargetType: "post", TargetId: "p1", CreatedAt: 400},
+		&pb.Event{Id: "5", Action: "auth", ActorId: "john", CreatedAt: 500},
+	)
+
+	tests := []struct {
+		name string
+		req  *pb.ListRequest
+		want string
+	}{
+		{"everything, newest first", &pb.ListRequest{}, "5,4,3,2,1"},
+		{"actor", &pb.ListRequest{ActorId: "jane"}, "2,1"},
+		{"action", &pb.ListRequest{Action: "auth.login"}, "1"},
+		{"action prefix", &pb.ListRequest{Action: "auth."}, "3,1"},
+		{"target type", &pb.ListRequest{TargetType: "post"}, "4,2"},
+		{"target", &pb.ListRequest{TargetType: "post", TargetId: "p1", ActorId: "admin"}, "4"},
+		{"since and until are inclusive", &pb.ListRequest{Since: 200, Until: 400}, "4,3,2"},
+		{"no match", &pb.ListRequest{ActorId: "nobody"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ids(t, h, tt.req); got != tt.want {
+				t.Errorf("List = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecordOnce(t *testing.T) {
+	h := newTestHandler(t)
+	ev := &pb.Event{Id: "1", Action: "auth.login"}
+	record(t, h, ev, ev)
+	// Events without an ID or action are dropped
+	record(t, h, &pb.Event{Action: "auth.login"}, &pb.Event{Id: "2"})
+
+	if got := ids(t, h, &pb.ListRequest{}); got != "1" {
+		t.Errorf("List = %q, want the redelivered event once", got)
+	}
+}
+
+func TestListCursor(t *testing.T) {
+	h := newTestHandler(t)
+	for i := 1; i <= 7; i++ {
+		record(t, h, &pb.Event{Id: fmt.Sprint(i), Action: "post.create", ActorId: "jane"})
+	}
+	// A filtered listing is paged over the matching events
+	record(t, h, &pb.Event{Id: "other", Action: "post.create", ActorId: "john"})
+
+	var pages []string
+	req := &pb.ListRequest{ActorId: "jane", Limit: 3}
+	for {
+		var rsp pb.ListResponse
+		if err := h.List(context.Background(), req, &rsp); err != nil {
+			t.Fatalf("List: %v", err)
+		}
+		var page []string
+		for _, ev := range rsp.Events {
+			page = append(page, ev.Id)
+		}
+		pages = append(pages, strings.Join(page, ","))
+		if rsp.NextCursor == "" {
+			break
+		}
+		req.Cursor = rsp.NextCursor
+	}
+	if got := strings.Join(pages, "|"); got != "7,6,5|4,3,2|1" {
+		t.Errorf("pages = %q, want 7,6,5|4,3,2|1", got)
+	}
+
+	err := h.List(context.Background(), &pb.ListRequest{Cursor: "not a cursor"}, &pb.ListResponse{})
+	if errors.FromError(err).Code != http.StatusBadRequest {
+		t.Errorf("List with a bad cursor = %v, want 400", err)
+	}
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"log"
+
+	"github.com/micro/blog/audit/handler"
+	pb "github.com/micro/blog/audit/proto"
+	"github.com/micro/blog/audit/recorder"
+	"go-micro.dev/v5"
+	"go-micro.dev/v5/server"
+)
+
+func main() {
+	service := micro.NewService(
+		micro.Name("audit"),
+	)
+
+	h := handler.New()
+	pb.RegisterAuditHandler(service.Server(), h)
+
+	// Every service publishes what it audits on one topic
+	if err := micro.RegisterSubscriber(recorder.Topic, service.Server(), h.Record, server.SubscriberQueue("audit")); err != nil {
+		log.Fatalf("Failed to subscribe to audit events: %v", err)
+	}
+
+	service.Init()
+
+	service.Run()
+}
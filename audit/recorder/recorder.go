@@ -0,0 +1,67 @@
+// Package recorder publishes audit events for the audit service to store.
+// Services record what they do through a Recorder; the gateway passes the
+// caller's user ID, IP and user agent as request metadata, which fills in
+// events that don't set them.
+package recorder
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"go-micro.dev/v5"
+	"go-micro.dev/v5/client"
+	"go-micro.dev/v5/metadata"
+
+	pb "github.com/micro/blog/audit/proto"
+)
+
+// Topic is where audit events are published
+const Topic = "audit"
+
+// Request metadata set by the gateway
+const (
+	UserIDKey    = "User-Id"
+	ClientIPKey  = "Client-Ip"
+	UserAgentKey = "User-Agent"
+)
+
+type Recorder struct {
+	service string
+	event   micro.Event
+}
+
+// New returns a Recorder publishing events on behalf of service
+func New(service string, c client.Client) *Recorder {
+	return &Recorder{service: service, event: micro.NewEvent(Topic, c)}
+}
+
+// Record publishes an event. A nil Recorder records nothing. Failures are
+// logged rather than returned, since the action has already happened.
+func (r *Recorder) Record(ctx context.Context, ev *pb.Event) {
+	if r == nil {
+		return
+	}
+	if ev.Id == "" {
+		ev.Id = uuid.New().String()
+	}
+	if ev.CreatedAt == 0 {
+		ev.CreatedAt = time.Now().Unix()
+	}
+	if ev.Service == "" {
+		ev.Service = r.service
+	}
+	if ev.ActorId == "" {
+		ev.ActorId, _ = metadata.Get(ctx, UserIDKey)
+	}
+	if ev.Ip == "" {
+		ev.Ip, _ = metadata.Get(ctx, ClientIPKey)
+	}
+	if ev.UserAgent == "" {
+		ev.UserAgent, _ = metadata.Get(ctx, UserAgentKey)
+	}
+	if err := r.event.Publish(ctx, ev); err != nil {
+		log.Printf("Failed to record audit event %s: %v", ev.Action, err)
+	}
+}
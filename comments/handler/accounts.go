@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+
+	pb "github.com/micro/blog/comments/proto"
+	users "github.com/micro/blog/users/proto"
+	"go-micro.dev/v5"
+	"go-micro.dev/v5/store"
+)
+
+// deletedAuthor replaces the name on anonymized comments
+const deletedAuthor = "Deleted user"
+
+// Accounts removes the comments of deleted users
+type Accounts struct {
+	progress micro.Event
+}
+
+// NewAccounts returns an Accounts reporting to the given DeletionProgress
+// publisher
+func NewAccounts(progress micro.Event) *Accounts {
+	return &Accounts{progress: progress}
+}
+
+// UserDeleted deletes or anonymizes the user's comments. It is subscribed to
+// the "user.deleted" topic and may see the same deletion more than once.
+func (a *Accounts) UserDeleted(ctx context.Context, ev *users.UserDeleted) error {
+	if ev.UserId == "" {
+		return nil
+	}
+	rec, err := commentStore.Read("comment-", store.ReadPrefix())
+	if err != nil {
+		return err
+	}
+	var items int32
+	for _, r := range rec {
+		var comment pb.Comment
+		if err := json.Unmarshal(r.Value, &comment); err != nil || comment.AuthorId != ev.UserId {
+			continue
+		}
+		switch ev.Mode {
+		case "delete":
+			err = commentStore.Delete(r.Key)
+		case "anonymize":
+			comment.AuthorId = ""
+			comment.AuthorName = deletedAuthor
+			comment.AuthorHandle = ""
+			b, _ := json.Marshal(&comment)
+			err = commentStore.Write(&store.Record{Key: r.Key, Value: b})
+		}
+		if err != nil {
+			// Report what was done; the deletion is retried
+			log.Printf("Failed to %s comment %s for deletion %s: %v", ev.Mode, comment.Id, ev.DeletionId, err)
+			break
+		}
+		items++
+	}
+	return a.progress.Publish(ctx, &users.DeletionProgress{
+		DeletionId: ev.DeletionId,
+		Service:    "comments",
+		Items:      items,
+		Done:       err == nil,
+	})
+}
@@ -0,0 +1,24 @@
+package handler
+
+import (
+	"context"
+
+	auditpb "github.com/micro/blog/audit/proto"
+	pb "github.com/micro/blog/comments/proto"
+)
+
+// record adds an event about a comment to the audit log. The actor is the
+// caller named in the request metadata, falling back to the author.
+func (h *Handler) record(ctx context.Context, action string, comment *pb.Comment) {
+	actor, _ := caller(ctx)
+	if actor == "" {
+		actor = comment.AuthorId
+	}
+	h.opts.Audit.Record(ctx, &auditpb.Event{
+		Action:     action,
+		ActorId:    actor,
+		TargetType: "comment",
+		TargetId:   comment.Id,
+		Details:    map[string]string{"author_id": comment.AuthorId, "post_id": comment.PostId},
+	})
+}
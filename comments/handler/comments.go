@@ -4,32 +4,84 @@ import (
 	"context"
 	"encoding/json"
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	pb "github.com/micro/blog/comments/proto"
+	posts "github.com/micro/blog/posts/proto"
+	users "github.com/micro/blog/users/proto"
+	"go-micro.dev/v5/errors"
 	"go-micro.dev/v5/store"
 )
 
 var commentStore = store.DefaultStore
 
-type Handler struct{}
+type Handler struct {
+	opts Options
+}
 
-func New() *Handler {
-	return &Handler{}
+func New(opts ...Option) *Handler {
+	var options Options
+	for _, o := range opts {
+		o(&options)
+	}
+	// Render comments written before Markdown support
+	renderExisting()
+	return &Handler{opts: options}
+}
+
+// checkBlocked refuses comments on posts the commenter can't read, which
+// includes missing posts and other authors' drafts, and from users the
+// post's author has blocked. It is skipped when the handler has no posts
+// or users client.
+func (h *Handler) checkBlocked(ctx context.Context, postID, authorID string) error {
+	if h.opts.Posts == nil || h.opts.Users == nil || postID == "" {
+		return nil
+	}
+	post, err := h.opts.Posts.Read(ctx, &posts.ReadRequest{Id: postID})
+	if err != nil {
+		return errors.InternalServerError("comments.Create", "failed to read post")
+	}
+	if post.Post == nil {
+		return errors.NotFound("comments.Create", "post not found")
+	}
+	if post.Post.AuthorId == "" {
+		return nil
+	}
+	rsp, err := h.opts.Users.IsBlocked(ctx, &users.IsBlockedRequest{
+		BlockerId: post.Post.AuthorId,
+		UserId:    authorID,
+	})
+	if err != nil {
+		return errors.InternalServerError("comments.Create", "failed to check blocks")
+	}
+	if rsp.Blocked {
+		return errors.Forbidden("comments.Create", "the author of this post has blocked you")
+	}
+	return nil
 }
 
 func (h *Handler) Create(ctx context.Context, req *pb.CreateRequest, rsp *pb.CreateResponse) error {
+	if strings.TrimSpace(req.Content) == "" {
+		return errors.BadRequest("comments.Create", "content required")
+	}
+	if err := h.checkBlocked(ctx, req.PostId, req.AuthorId); err != nil {
+		return err
+	}
+
 	id := uuid.New().String()
 	now := time.Now().Unix()
 
 	comment := &pb.Comment{
-		Id:         id,
-		Content:    req.Content,
-		AuthorId:   req.AuthorId,
-		AuthorName: req.AuthorName,
-		PostId:     req.PostId,
-		CreatedAt:  now,
+		Id:           id,
+		Content:      req.Content,
+		AuthorId:     req.AuthorId,
+		AuthorName:   req.AuthorName,
+		AuthorHandle: req.AuthorHandle,
+		PostId:       req.PostId,
+		ContentHtml:  renderContent(req.Content),
+		CreatedAt:    now,
 	}
 
 	// Extract first URL and fetch link preview
@@ -53,6 +105,7 @@ func (h *Handler) Create(ctx context.Context, req *pb.CreateRequest, rsp *pb.Cre
 	if err == nil {
 		_ = commentStore.Write(&store.Record{Key: "comment-" + comment.Id, Value: b})
 	}
+	h.record(ctx, "comment.create", comment)
 
 	return nil
 }
@@ -71,30 +124,67 @@ func (h *Handler) Read(ctx context.Context, req *pb.ReadRequest, rsp *pb.ReadRes
 }
 
 func (h *Handler) Update(ctx context.Context, req *pb.UpdateRequest, rsp *pb.UpdateResponse) error {
+	if strings.TrimSpace(req.Content) == "" {
+		return errors.BadRequest("comments.Update", "content required")
+	}
 	rec, err := commentStore.Read("comment-" + req.Id)
 	if err != nil || len(rec) == 0 {
-		rsp.Comment = nil
-		return nil
+		return errors.NotFound("comments.Update", "comment not found")
 	}
 	var comment pb.Comment
 	if err := json.Unmarshal(rec[0].Value, &comment); err != nil {
-		rsp.Comment = nil
-		return nil
+		return errors.InternalServerError("comments.Update", "failed to decode comment")
 	}
+	if err := authorize(ctx, "comments.Update", comment.AuthorId); err != nil {
+		return err
+	}
+	// Only the content changes; the author and post stay as they were
 	comment.Content = req.Content
-	comment.AuthorId = req.UserId // UpdateRequest now uses user_id
-	comment.PostId = req.PostId
-	comment.CreatedAt = comment.CreatedAt // preserve original timestamp
+	comment.ContentHtml = renderContent(req.Content)
+
+	url := extractFirstURL(req.Content)
+	if url == "" {
+		comment.LinkPreview = nil
+	} else if comment.LinkPreview == nil || comment.LinkPreview.Url != url {
+		comment.LinkPreview = nil
+		if title, desc, image, err := fetchLinkPreview(url); err == nil {
+			comment.LinkPreview = &pb.LinkPreview{
+				Url:         url,
+				Title:       title,
+				Description: desc,
+				Image:       image,
+			}
+		}
+	}
+
 	b, err := json.Marshal(&comment)
-	if err == nil {
-		_ = commentStore.Write(&store.Record{Key: "comment-" + comment.Id, Value: b})
+	if err != nil {
+		return errors.InternalServerError("comments.Update", "failed to encode comment")
+	}
+	if err := commentStore.Write(&store.Record{Key: "comment-" + comment.Id, Value: b}); err != nil {
+		return errors.InternalServerError("comments.Update", "failed to save comment")
 	}
+	h.record(ctx, "comment.update", &comment)
 	rsp.Comment = &comment
 	return nil
 }
 
 func (h *Handler) Delete(ctx context.Context, req *pb.DeleteRequest, rsp *pb.DeleteResponse) error {
-	_ = commentStore.Delete("comment-" + req.Id)
+	rec, err := commentStore.Read("comment-" + req.Id)
+	if err != nil || len(rec) == 0 {
+		return errors.NotFound("comments.Delete", "comment not found")
+	}
+	var comment pb.Comment
+	if err := json.Unmarshal(rec[0].Value, &comment); err != nil {
+		return errors.InternalServerError("comments.Delete", "failed to decode comment")
+	}
+	if err := authorize(ctx, "comments.Delete", comment.AuthorId); err != nil {
+		return err
+	}
+	if err := commentStore.Delete("comment-" + req.Id); err != nil {
+		return errors.InternalServerError("comments.Delete", "failed to delete comment")
+	}
+	h.record(ctx, "comment.delete", &comment)
 	return nil
 }
 
@@ -105,7 +195,7 @@ func (h *Handler) List(ctx context.Context, req *pb.ListRequest, rsp *pb.ListRes
 		for _, r := range rec {
 			var c pb.Comment
 			if err := json.Unmarshal(r.Value, &c); err == nil {
-				if req.PostId == "" || c.PostId == req.PostId {
+				if (req.PostId == "" || c.PostId == req.PostId) && (req.AuthorId == "" || c.AuthorId == req.AuthorId) {
 					loadedComments = append(loadedComments, &c)
 				}
 			}
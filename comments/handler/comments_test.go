@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	pb "github.com/micro/blog/comments/proto"
+	posts "github.com/micro/blog/posts/proto"
+	"go-micro.dev/v5/errors"
+)
+
+func TestCreateChecksPost(t *testing.T) {
+	h := newTestHandler(t,
+		WithPosts(fakePosts{posts: map[string]*posts.Post{"post-1": {Id: "post-1", AuthorId: "jane"}}}),
+		WithUsers(fakeUsers{blocks: map[string][]string{"jane": {"mallory"}}}),
+	)
+
+	if c, err := comment(h, "post-1", "john", "Nice post"); err != nil || c == nil {
+		t.Fatalf("Create = %v, %v", c, err)
+	}
+	tests := []struct {
+		name, postID, authorID string
+		want                   int32
+	}{
+		{"blocked", "post-1", "mallory", http.StatusForbidden},
+		// A post the commenter can't read, e.g. another author's draft
+		{"missing post", "draft", "john", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := comment(h, tt.postID, tt.authorID, "Hello")
+			if errors.FromError(err).Code != tt.want {
+				t.Errorf("Create = %v, want %d", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestEmptyContent(t *testing.T) {
+	h := newTestHandler(t)
+	for _, content := range []string{"", " \n\t"} {
+		if _, err := comment(h, "post-1", "jane", content); errors.FromError(err).Code != http.StatusBadRequest {
+			t.Errorf("Create(%q) = %v, want 400", content, err)
+		}
+	}
+
+	c, err := comment(h, "post-1", "jane", "First thoughts")
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	for _, content := range []string{"", "   "} {
+		err := h.Update(as("jane"), &pb.UpdateRequest{Id: c.Id, Content: content}, &pb.UpdateResponse{})
+		if errors.FromError(err).Code != http.StatusBadRequest {
+			t.Errorf("Update(%q) = %v, want 400", content, err)
+		}
+	}
+	var read pb.ReadResponse
+	if err := h.Read(as("jane"), &pb.ReadRequest{Id: c.Id}, &read); err != nil || read.Comment.Content != "First thoughts" {
+		t.Errorf("comment after rejected updates: %+v (%v)", read.Comment, err)
+	}
+
+	var updated pb.UpdateResponse
+	if err := h.Update(as("jane"), &pb.UpdateRequest{Id: c.Id, Content: "Second thoughts"}, &updated); err != nil || updated.Comment.Content != "Second thoughts" {
+		t.Errorf("Update = %+v, %v", updated.Comment, err)
+	}
+}
+
+func TestPostDeleted(t *testing.T) {
+	h := newTestHandler(t, WithPosts(fakePosts{posts: map[string]*posts.Post{
+		"post-1": {Id: "post-1", AuthorId: "jane"},
+		"post-2": {Id: "post-2", AuthorId: "jane"},
+	}}))
+	for _, postID := range []string{"post-1", "post-1", "post-2"} {
+		if _, err := comment(h, postID, "john", "Nice post"); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+	}
+
+	if err := PostDeleted(context.Background(), &posts.PostDeleted{PostId: "post-1"}); err != nil {
+		t.Fatalf("PostDeleted: %v", err)
+	}
+	var list pb.ListResponse
+	if err := h.List(context.Background(), &pb.ListRequest{AuthorId: "john"}, &list); err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(list.Comments) != 1 || list.Comments[0].PostId != "post-2" {
+		t.Errorf("comments left = %v, want the one on post-2", list.Comments)
+	}
+}
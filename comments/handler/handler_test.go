@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"context"
+	"testing"
+
+	pb "github.com/micro/blog/comments/proto"
+	posts "github.com/micro/blog/posts/proto"
+	users "github.com/micro/blog/users/proto"
+	"go-micro.dev/v5/client"
+	"go-micro.dev/v5/metadata"
+	"go-micro.dev/v5/store"
+)
+
+// fakePosts serves posts by ID; any other ID reads as nil, as a missing
+// post or another author's draft does
+type fakePosts struct {
+	posts.PostsService
+	posts map[string]*posts.Post
+}
+
+func (f fakePosts) Read(ctx context.Context, in *posts.ReadRequest, opts ...client.CallOption) (*posts.ReadResponse, error) {
+	return &posts.ReadResponse{Post: f.posts[in.Id]}, nil
+}
+
+// fakeUsers reports blocks as blocker -> blocked users
+type fakeUsers struct {
+	users.UsersService
+	blocks map[string][]string
+}
+
+func (f fakeUsers) IsBlocked(ctx context.Context, in *users.IsBlockedRequest, opts ...client.CallOption) (*users.IsBlockedResponse, error) {
+	for _, id := range f.blocks[in.BlockerId] {
+		if id == in.UserId {
+			return &users.IsBlockedResponse{Blocked: true}, nil
+		}
+	}
+	return &users.IsBlockedResponse{}, nil
+}
+
+// newTestHandler returns a handler with an empty store and the given
+// options
+func newTestHandler(t *testing.T, opts ...Option) *Handler {
+	t.Helper()
+	// The file store the service runs with; the memory store's prefix reads
+	// return nothing without a limit
+	commentStore = store.NewFileStore(store.DirOption(t.TempDir()))
+	t.Cleanup(func() { commentStore.Close() })
+	return New(opts...)
+}
+
+// as returns a context calling as a user
+func as(userID string) context.Context {
+	return metadata.NewContext(context.Background(), metadata.Metadata{userIDKey: userID})
+}
+
+// comment creates a comment by a user on a post
+func comment(h *Handler, postID, authorID, content string) (*pb.Comment, error) {
+	var rsp pb.CreateResponse
+	err := h.Create(as(authorID), &pb.CreateRequest{PostId: postID, AuthorId: authorID, Content: content}, &rsp)
+	return rsp.Comment, err
+}
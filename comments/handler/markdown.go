@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"html"
+	"log"
+	"regexp"
+
+	pb "github.com/micro/blog/comments/proto"
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	"go-micro.dev/v5/store"
+)
+
+// markdown renders CommonMark with the GitHub extensions: tables, task
+// lists, strikethrough and bare links. Raw HTML in the source is dropped.
+var markdown = goldmark.New(goldmark.WithExtensions(extension.GFM))
+
+// commentPolicy is stricter than the one for posts: text formatting, links,
+// lists, quotes, code and task list checkboxes, but no headings or tables,
+// which are left as their text, and no images
+var commentPolicy = func() *bluemonday.Policy {
+	p := bluemonday.NewPolicy()
+	p.AllowStandardURLs()
+	p.AllowElements("p", "br", "strong", "em", "del", "blockquote", "pre", "code", "hr")
+	p.AllowLists()
+	p.AllowAttrs("href").OnElements("a")
+	p.RequireNoFollowOnLinks(true)
+	p.AddTargetBlankToFullyQualifiedLinks(true)
+	p.AllowAttrs("type").Matching(regexp.MustCompile(`^checkbox$`)).OnElements("input")
+	p.AllowAttrs("checked", "disabled").Matching(regexp.MustCompile(`^$`)).OnElements("input")
+	p.AllowAttrs("class").Matching(regexp.MustCompile(`^language-[\w+-]+$`)).OnElements("code")
+	return p
+}()
+
+// renderContent renders comment content from Markdown to sanitized HTML
+func renderContent(content string) string {
+	var buf bytes.Buffer
+	if err := markdown.Convert([]byte(content), &buf); err != nil {
+		return html.EscapeString(content)
+	}
+	return commentPolicy.Sanitize(buf.String())
+}
+
+// renderExisting renders comments written before content_html existed
+func renderExisting() {
+	rec, err := commentStore.Read("comment-", store.ReadPrefix())
+	if err != nil {
+		return
+	}
+	for _, r := range rec {
+		var comment pb.Comment
+		if err := json.Unmarshal(r.Value, &comment); err != nil || comment.Content == "" || comment.ContentHtml != "" {
+			continue
+		}
+		comment.ContentHtml = renderContent(comment.Content)
+		b, _ := json.Marshal(&comment)
+		if err := commentStore.Write(&store.Record{Key: r.Key, Value: b}); err != nil {
+			log.Printf("Failed to render comment %s: %v", comment.Id, err)
+		}
+	}
+}
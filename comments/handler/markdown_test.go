@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderContent(t *testing.T) {
+	tests := []struct {
+		name, content string
+		want          []string
+	}{
+		{"emphasis", "Some *emphasis* and ~~struck~~", []string{"<em>emphasis</em>", "<del>struck</del>"}},
+		{"link", "[site](https://example.com)", []string{`href="https://example.com"`, `rel="nofollow noopener"`, `target="_blank"`}},
+		{"code", "`x := 1`", []string{"<code>x := 1</code>"}},
+		{"task list", "- [ ] todo", []string{"<li>", `type="checkbox"`}},
+		// Headings are left as their text and images are dropped
+		{"heading", "# Title", []string{"Title"}},
+		{"image", "![alt text](https://example.com/a.png)", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := renderContent(tt.content)
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("renderContent(%q) = %q, want it to contain %q", tt.content, got, want)
+				}
+			}
+			for _, bad := range []string{"<h1", "<img"} {
+				if strings.Contains(got, bad) {
+					t.Errorf("renderContent(%q) = %q, contains %q", tt.content, got, bad)
+				}
+			}
+		})
+	}
+}
+
+func TestRenderContentSanitizes(t *testing.T) {
+	for _, content := range []string{
+		"<script>alert(1)</script>",
+		`<img src="x" onerror="alert(1)">`,
+		"[click](javascript:alert(1))",
+		"[click](data:text/html;base64,PHNjcmlwdD4=)",
+	} {
+		got := renderContent(content)
+		for _, bad := range []string{"<script", "onerror", "javascript:", "data:"} {
+			if strings.Contains(got, bad) {
+				t.Errorf("renderContent(%q) = %q, contains %q", content, got, bad)
+			}
+		}
+	}
+}
+
+// The policy is the defence if the Markdown renderer ever passes HTML through
+func TestCommentPolicy(t *testing.T) {
+	tests := []struct {
+		html, want string
+	}{
+		{"<p>hi<script>alert(1)</script></p>", "<p>hi</p>"},
+		{`<img src="https://example.com/a.png" onerror="alert(1)">`, ""},
+		{`<a href="javascript:alert(1)">x</a>`, "x"},
+		{`<a href="data:text/html,hi">x</a>`, "x"},
+		{`<p onclick="alert(1)">hi</p>`, "<p>hi</p>"},
+		{`<h1>Title</h1>`, "Title"},
+	}
+	for _, tt := range tests {
+		if got := commentPolicy.Sanitize(tt.html); got != tt.want {
+			t.Errorf("Sanitize(%q) = %q, want %q", tt.html, got, tt.want)
+		}
+	}
+}
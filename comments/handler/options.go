@@ -0,0 +1,36 @@
+package handler
+
+import (
+	"github.com/micro/blog/audit/recorder"
+	posts "github.com/micro/blog/posts/proto"
+	users "github.com/micro/blog/users/proto"
+)
+
+type Options struct {
+	// Posts looks up the author of the post being commented on
+	Posts posts.PostsService
+	// Users checks whether that author has blocked the commenter
+	Users users.UsersService
+	// Audit records comment changes in the audit log
+	Audit *recorder.Recorder
+}
+
+type Option func(o *Options)
+
+func WithPosts(s posts.PostsService) Option {
+	return func(o *Options) {
+		o.Posts = s
+	}
+}
+
+func WithUsers(s users.UsersService) Option {
+	return func(o *Options) {
+		o.Users = s
+	}
+}
+
+func WithAudit(r *recorder.Recorder) Option {
+	return func(o *Options) {
+		o.Audit = r
+	}
+}
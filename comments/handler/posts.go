@@ -0,0 +1,33 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+
+	pb "github.com/micro/blog/comments/proto"
+	posts "github.com/micro/blog/posts/proto"
+	"go-micro.dev/v5/store"
+)
+
+// PostDeleted deletes the comments on a deleted post, whether its author
+// deleted it or it went with their account. It is subscribed to the
+// "post.deleted" topic.
+func PostDeleted(ctx context.Context, ev *posts.PostDeleted) error {
+	if ev.PostId == "" {
+		return nil
+	}
+	rec, err := commentStore.Read("comment-", store.ReadPrefix())
+	if err != nil {
+		return err
+	}
+	for _, r := range rec {
+		var comment pb.Comment
+		if err := json.Unmarshal(r.Value, &comment); err != nil || comment.PostId != ev.PostId {
+			continue
+		}
+		if err := commentStore.Delete(r.Key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -1,9 +1,15 @@
 package main
 
 import (
+	"log"
+
+	"github.com/micro/blog/audit/recorder"
 	"github.com/micro/blog/comments/handler"
 	pb "github.com/micro/blog/comments/proto"
+	posts "github.com/micro/blog/posts/proto"
+	users "github.com/micro/blog/users/proto"
 	"go-micro.dev/v5"
+	"go-micro.dev/v5/server"
 )
 
 func main() {
@@ -11,7 +17,21 @@ func main() {
 		micro.Name("comments"),
 	)
 
-	pb.RegisterCommentsHandler(service.Server(), handler.New())
+	pb.RegisterCommentsHandler(service.Server(), handler.New(
+		handler.WithPosts(posts.NewPostsService("posts", service.Client())),
+		handler.WithUsers(users.NewUsersService("users", service.Client())),
+		handler.WithAudit(recorder.New("comments", service.Client())),
+	))
+
+	// Delete or anonymize the comments of deleted accounts
+	accounts := handler.NewAccounts(micro.NewEvent("user.deletion.progress", service.Client()))
+	if err := micro.RegisterSubscriber("user.deleted", service.Server(), accounts.UserDeleted, server.SubscriberQueue("comments")); err != nil {
+		log.Fatalf("Failed to subscribe to user deletions: %v", err)
+	}
+	// and the comments on deleted posts
+	if err := micro.RegisterSubscriber("post.deleted", service.Server(), handler.PostDeleted, server.SubscriberQueue("comments")); err != nil {
+		log.Fatalf("Failed to subscribe to post deletions: %v", err)
+	}
 
 	service.Init()
 
@@ -0,0 +1,107 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+
+	pb "github.com/micro/blog/posts/proto"
+	users "github.com/micro/blog/users/proto"
+	"go-micro.dev/v5"
+	"go-micro.dev/v5/store"
+)
+
+// deletedAuthor replaces the name on anonymized posts
+const deletedAuthor = "Deleted user"
+
+// Accounts removes the posts of deleted users
+type Accounts struct {
+	progress micro.Event
+	deleted  micro.Event
+}
+
+// NewAccounts returns an Accounts reporting to the given DeletionProgress
+// publisher and announcing deleted posts with the PostDeleted one
+func NewAccounts(progress, deleted micro.Event) *Accounts {
+	return &Accounts{progress: progress, deleted: deleted}
+}
+
+// UserDeleted deletes or anonymizes the user's posts. It is subscribed to
+// the "user.deleted" topic and may see the same deletion more than once.
+func (a *Accounts) UserDeleted(ctx context.Context, ev *users.UserDeleted) error {
+	if ev.UserId == "" {
+		return nil
+	}
+	items, deleted, err := removePosts(ev)
+	// The comments on deleted posts go too
+	for _, id := range deleted {
+		publishDeleted(ctx, a.deleted, id)
+	}
+	return a.progress.Publish(ctx, &users.DeletionProgress{
+		DeletionId: ev.DeletionId,
+		Service:    "posts",
+		Items:      items,
+		Done:       err == nil,
+	})
+}
+
+// removePosts deletes or anonymizes the user's posts under mu, so an edit
+// can't bring one back. It returns how many it handled and the IDs of those
+// it deleted.
+func removePosts(ev *users.UserDeleted) (int32, []string, error) {
+	mu.Lock()
+	defer mu.Unlock()
+	rec, err := postStore.Read("post-", store.ReadPrefix())
+	if err != nil {
+		return 0, nil, err
+	}
+	var items int32
+	var deleted []string
+	for _, r := range rec {
+		var post pb.Post
+		if err := json.Unmarshal(r.Value, &post); err != nil || post.AuthorId != ev.UserId {
+			continue
+		}
+		switch ev.Mode {
+		case "delete":
+			if err = postStore.Delete(r.Key); err == nil {
+				deleted = append(deleted, post.Id)
+				err = deleteRevisions(post.Id)
+			}
+		case "anonymize":
+			post.AuthorId = ""
+			post.AuthorName = deletedAuthor
+			post.AuthorHandle = ""
+			b, _ := json.Marshal(&post)
+			err = postStore.Write(&store.Record{Key: r.Key, Value: b})
+		}
+		if err != nil {
+			// Report what was done; the deletion is retried
+			log.Printf("Failed to %s post %s for deletion %s: %v", ev.Mode, post.Id, ev.DeletionId, err)
+			return items, deleted, err
+		}
+		items++
+	}
+	return items, deleted, forgetEditor(ev.UserId)
+}
+
+// forgetEditor removes a deleted user from the revisions they saved, such as
+// a moderator's edits of other people's posts
+func forgetEditor(userID string) error {
+	rec, err := postStore.Read("revision-", store.ReadPrefix())
+	if err != nil {
+		return err
+	}
+	for _, r := range rec {
+		var rev pb.Revision
+		if err := json.Unmarshal(r.Value, &rev); err != nil || rev.EditorId != userID {
+			continue
+		}
+		rev.EditorId = ""
+		b, _ := json.Marshal(&rev)
+		if err := postStore.Write(&store.Record{Key: r.Key, Value: b}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"context"
+	"testing"
+
+	pb "github.com/micro/blog/posts/proto"
+	users "github.com/micro/blog/users/proto"
+)
+
+// deletedPosts returns the IDs of the posts in PostDeleted events
+func deletedPosts(e *events) []string {
+	var ids []string
+	for _, msg := range e.msgs {
+		if ev, ok := msg.(*pb.PostDeleted); ok {
+			ids = append(ids, ev.PostId)
+		}
+	}
+	return ids
+}
+
+func TestDeletePublishesPostDeleted(t *testing.T) {
+	deleted := &events{}
+	h := newTestHandler(t, WithPostDeleted(deleted))
+	var created pb.CreateResponse
+	if err := h.Create(as("jane"), &pb.CreateRequest{Title: "Hello", AuthorId: "jane"}, &created); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	if err := h.Delete(as("mallory"), &pb.DeleteRequest{Id: created.Post.Id}, &pb.DeleteResponse{}); err == nil {
+		t.Fatal("Delete by another user succeeded")
+	}
+	if ids := deletedPosts(deleted); len(ids) != 0 {
+		t.Fatalf("a rejected Delete published %v", ids)
+	}
+	if err := h.Delete(as("jane"), &pb.DeleteRequest{Id: created.Post.Id}, &pb.DeleteResponse{}); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if ids := deletedPosts(deleted); len(ids) != 1 || ids[0] != created.Post.Id {
+		t.Errorf("Delete published %v, want %s", ids, created.Post.Id)
+	}
+}
+
+func TestUserDeletedPublishesPostDeleted(t *testing.T) {
+	h := newTestHandler(t)
+	createPosts(t, h, "jane", 2)
+	createPosts(t, h, "john", 1)
+
+	progress, deleted := &events{}, &events{}
+	a := NewAccounts(progress, deleted)
+	ev := &users.UserDeleted{DeletionId: "d1", UserId: "jane", Mode: "delete"}
+	if err := a.UserDeleted(context.Background(), ev); err != nil {
+		t.Fatalf("UserDeleted: %v", err)
+	}
+	if ids := deletedPosts(deleted); len(ids) != 2 {
+		t.Errorf("deleting jane published PostDeleted for %v, want her 2 posts", ids)
+	}
+	if p, ok := progress.msgs[0].(*users.DeletionProgress); !ok || !p.Done || p.Items != 2 {
+		t.Errorf("progress = %+v, want done with 2 items", progress.msgs[0])
+	}
+	var list pb.ListResponse
+	if err := h.List(context.Background(), &pb.ListRequest{}, &list); err != nil || len(list.Posts) != 1 || list.Posts[0].AuthorId != "john" {
+		t.Errorf("posts left = %v (%v), want john's", list.Posts, err)
+	}
+
+	// Anonymizing keeps the posts, and so their comments
+	deleted.msgs = nil
+	ev = &users.UserDeleted{DeletionId: "d2", UserId: "john", Mode: "anonymize"}
+	if err := a.UserDeleted(context.Background(), ev); err != nil {
+		t.Fatalf("UserDeleted: %v", err)
+	}
+	if ids := deletedPosts(deleted); len(ids) != 0 {
+		t.Errorf("anonymizing published PostDeleted for %v", ids)
+	}
+}
@@ -0,0 +1,24 @@
+package handler
+
+import (
+	"context"
+
+	auditpb "github.com/micro/blog/audit/proto"
+	pb "github.com/micro/blog/posts/proto"
+)
+
+// record adds an event about a post to the audit log. The actor is the
+// caller named in the request metadata, falling back to the author.
+func (h *Handler) record(ctx context.Context, action string, post *pb.Post) {
+	actor, _ := caller(ctx)
+	if actor == "" {
+		actor = post.AuthorId
+	}
+	h.opts.Audit.Record(ctx, &auditpb.Event{
+		Action:     action,
+		ActorId:    actor,
+		TargetType: "post",
+		TargetId:   post.Id,
+		Details:    map[string]string{"author_id": post.AuthorId, "title": post.Title, "status": post.Status},
+	})
+}
@@ -0,0 +1,39 @@
+package handler
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"go-micro.dev/v5/errors"
+	"go-micro.dev/v5/metadata"
+)
+
+// The gateway identifies the caller of write RPCs with these metadata keys.
+// User-Permissions is a comma separated list such as "content:moderate".
+const (
+	userIDKey          = "User-Id"
+	userPermissionsKey = "User-Permissions"
+)
+
+// caller returns the calling user's ID and permissions from the request metadata
+func caller(ctx context.Context) (string, []string) {
+	id, _ := metadata.Get(ctx, userIDKey)
+	perms, _ := metadata.Get(ctx, userPermissionsKey)
+	if perms == "" {
+		return id, nil
+	}
+	return id, strings.Split(perms, ",")
+}
+
+// authorize allows the author of a resource and moderators to change it
+func authorize(ctx context.Context, method, authorID string) error {
+	id, perms := caller(ctx)
+	if id == "" {
+		return errors.Unauthorized(method, "caller identity required")
+	}
+	if id != authorID && !slices.Contains(perms, "content:moderate") {
+		return errors.Forbidden(method, "only the author or a moderator can do this")
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+package handler
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	pb "github.com/micro/blog/posts/proto"
+	"go-micro.dev/v5/errors"
+	"go-micro.dev/v5/store"
+)
+
+const (
+	defaultFeedLimit = 20
+	maxFeedLimit     = 100
+)
+
+// postCursor is the position after the last post of a page of the feed or
+// List. Posts are ordered by publish time and then ID, both descending, so
+// posts published while paging don't shift later pages.
+type postCursor struct {
+	at int64
+	id string
+}
+
+func (c postCursor) String() string {
+	return base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("%d:%s", c.at, c.id)))
+}
+
+func parsePostCursor(s string) (postCursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return postCursor{}, err
+	}
+	ts, id, ok := strings.Cut(string(b), ":")
+	if !ok || id == "" {
+		return postCursor{}, fmt.Errorf("malformed cursor")
+	}
+	at, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return postCursor{}, err
+	}
+	return postCursor{at: at, id: id}, nil
+}
+
+// before reports whether p comes after the cursor in newest first order
+func (c postCursor) before(p *pb.Post) bool {
+	if t := postTime(p); t != c.at {
+		return t < c.at
+	}
+	return p.Id < c.id
+}
+
+// sortNewestFirst orders posts by publish time and then ID, both descending
+func sortNewestFirst(posts []*pb.Post) {
+	sort.Slice(posts, func(i, j int) bool {
+		if ti, tj := postTime(posts[i]), postTime(posts[j]); ti != tj {
+			return ti > tj
+		}
+		return posts[i].Id > posts[j].Id
+	})
+}
+
+// inFeed reports whether a post is by one of the authors or has one of the
+// tags. Tags match case insensitively.
+func inFeed(p *pb.Post, authors map[string]bool, tags []string) bool {
+	if authors[p.AuthorId] {
+		return true
+	}
+	for _, t := range p.Tags {
+		for _, want := range tags {
+			if strings.EqualFold(t, want) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (h *Handler) Feed(ctx context.Context, req *pb.FeedRequest, rsp *pb.FeedResponse) error {
+	limit := int(req.Limit)
+	if limit <= 0 {
+		limit = defaultFeedLimit
+	}
+	if limit > maxFeedLimit {
+		limit = maxFeedLimit
+	}
+	var cursor *postCursor
+	if req.Cursor != "" {
+		c, err := parsePostCursor(req.Cursor)
+		if err != nil {
+			return errors.BadRequest("posts.Feed", "invalid cursor")
+		}
+		cursor = &c
+	}
+	if len(req.AuthorIds) == 0 && len(req.Tags) == 0 {
+		return nil
+	}
+	authors := map[string]bool{}
+	for _, id := range req.AuthorIds {
+		authors[id] = true
+	}
+	excluded := map[string]bool{}
+	for _, id := range req.ExcludeAuthorIds {
+		excluded[id] = true
+	}
+
+	rec, err := postStore.Read("post-", store.ReadPrefix())
+	if err != nil {
+		return errors.InternalServerError("posts.Feed", "failed to read posts")
+	}
+	now := time.Now()
+	var posts []*pb.Post
+	for _, r := range rec {
+		var p pb.Post
+		if err := json.Unmarshal(r.Value, &p); err != nil || !isPublished(&p, now) {
+			continue
+		}
+		p.Status = statusPublished
+		if !excluded[p.AuthorId] && inFeed(&p, authors, req.Tags) && (cursor == nil || cursor.before(&p)) {
+			posts = append(posts, &p)
+		}
+	}
+	sortNewestFirst(posts)
+
+	if len(posts) > limit {
+		posts = posts[:limit]
+		last := posts[limit-1]
+		rsp.NextCursor = postCursor{at: postTime(last), id: last.Id}.String()
+	}
+	rsp.Posts = posts
+	return nil
+}
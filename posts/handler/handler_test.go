@@ -0,0 +1,49 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	pb "github.com/micro/blog/posts/proto"
+	"go-micro.dev/v5/client"
+	"go-micro.dev/v5/metadata"
+	"go-micro.dev/v5/store"
+)
+
+// newTestHandler returns a handler with an empty store, no scheduler and
+// the given options
+func newTestHandler(t *testing.T, opts ...Option) *Handler {
+	t.Helper()
+	// The file store the service runs with; the memory store's prefix reads
+	// return nothing without a limit
+	postStore = store.NewFileStore(store.DirOption(t.TempDir()))
+	t.Cleanup(func() { postStore.Close() })
+	return New(append([]Option{WithPublishInterval(0)}, opts...)...)
+}
+
+// events records what is published
+type events struct {
+	msgs []any
+}
+
+func (e *events) Publish(ctx context.Context, msg any, opts ...client.PublishOption) error {
+	e.msgs = append(e.msgs, msg)
+	return nil
+}
+
+// as returns a context calling as a user
+func as(userID string) context.Context {
+	return metadata.NewContext(context.Background(), metadata.Metadata{userIDKey: userID})
+}
+
+// createPosts publishes n posts by an author
+func createPosts(t *testing.T, h *Handler, authorID string, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		req := &pb.CreateRequest{Title: fmt.Sprintf("Post %d", i), Content: "Hello", AuthorId: authorID, AuthorName: authorID}
+		if err := h.Create(as(authorID), req, &pb.CreateResponse{}); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+	}
+}
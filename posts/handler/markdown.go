@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"html"
+	"log"
+	"regexp"
+
+	pb "github.com/micro/blog/posts/proto"
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	"go-micro.dev/v5/store"
+)
+
+// markdown renders CommonMark with the GitHub extensions: tables, task
+// lists, strikethrough and bare links. Raw HTML in the source is dropped.
+var markdown = goldmark.New(goldmark.WithExtensions(extension.GFM))
+
+// postPolicy is what post HTML may contain: the usual user content elements,
+// including headings, images and tables, plus task list checkboxes and code
+// language classes
+var postPolicy = func() *bluemonday.Policy {
+	p := bluemonday.UGCPolicy()
+	p.AllowAttrs("type").Matching(regexp.MustCompile(`^checkbox$`)).OnElements("input")
+	p.AllowAttrs("checked", "disabled").Matching(regexp.MustCompile(`^$`)).OnElements("input")
+	p.AllowAttrs("class").Matching(regexp.MustCompile(`^language-[\w+-]+$`)).OnElements("code")
+	p.AddTargetBlankToFullyQualifiedLinks(true)
+	return p
+}()
+
+// renderContent renders post content from Markdown to sanitized HTML
+func renderContent(content string) string {
+	var buf bytes.Buffer
+	if err := markdown.Convert([]byte(content), &buf); err != nil {
+		return html.EscapeString(content)
+	}
+	return postPolicy.Sanitize(buf.String())
+}
+
+// renderExisting renders posts written before content_html existed
+func renderExisting() {
+	mu.Lock()
+	defer mu.Unlock()
+
+	rec, err := postStore.Read("post-", store.ReadPrefix())
+	if err != nil {
+		return
+	}
+	for _, r := range rec {
+		var post pb.Post
+		if err := json.Unmarshal(r.Value, &post); err != nil || post.Content == "" || post.ContentHtml != "" {
+			continue
+		}
+		post.ContentHtml = renderContent(post.Content)
+		b, _ := json.Marshal(&post)
+		if err := postStore.Write(&store.Record{Key: r.Key, Value: b}); err != nil {
+			log.Printf("Failed to render post %s: %v", post.Id, err)
+		}
+	}
+}
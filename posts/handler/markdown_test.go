@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderContent(t *testing.T) {
+	tests := []struct {
+		name, content string
+		want          []string
+	}{
+		{"emphasis", "Some *emphasis* and **bold**", []string{"<em>emphasis</em>", "<strong>bold</strong>"}},
+		{"heading", "# Title", []string{"<h1"}},
+		{"link", "[site](https://example.com)", []string{`href="https://example.com"`, `target="_blank"`}},
+		{"image", "![alt](https://example.com/a.png)", []string{`<img src="https://example.com/a.png"`}},
+		{"table", "| a |\n|---|\n| b |", []string{"<table>", "<td>b</td>"}},
+		{"task list", "- [x] done", []string{`type="checkbox"`, "checked"}},
+		{"code", "```go\nfmt.Println()\n```", []string{`<code class="language-go">`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := renderContent(tt.content)
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("renderContent(%q) = %q, want it to contain %q", tt.content, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestRenderContentSanitizes(t *testing.T) {
+	for _, content := range []string{
+		"<script>alert(1)</script>",
+		`<img src="x" onerror="alert(1)">`,
+		"[click](javascript:alert(1))",
+		"[click](data:text/html;base64,PHNjcmlwdD4=)",
+		"![img](javascript:alert(1))",
+		"![img](data:image/svg+xml;base64,PHN2Zz4=)",
+	} {
+		got := renderContent(content)
+		for _, bad := range []string{"<script", "onerror", "javascript:", "data:"} {
+			if strings.Contains(got, bad) {
+				t.Errorf("renderContent(%q) = %q, contains %q", content, got, bad)
+			}
+		}
+	}
+}
+
+// The policy is the defence if the Markdown renderer ever passes HTML through
+func TestPostPolicy(t *testing.T) {
+	tests := []struct {
+		html, want string
+	}{
+		{"<p>hi<script>alert(1)</script></p>", "<p>hi</p>"},
+		{`<img src="https://example.com/a.png" onerror="alert(1)">`, `<img src="https://example.com/a.png">`},
+		{`<a href="javascript:alert(1)">x</a>`, "x"},
+		{`<img src="data:image/png;base64,AAAA">`, ""},
+		{`<p onclick="alert(1)" style="color:red">hi</p>`, "<p>hi</p>"},
+		{`<iframe src="https://example.com"></iframe>`, ""},
+	}
+	for _, tt := range tests {
+		if got := postPolicy.Sanitize(tt.html); got != tt.want {
+			t.Errorf("Sanitize(%q) = %q, want %q", tt.html, got, tt.want)
+		}
+	}
+}
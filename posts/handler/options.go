@@ -0,0 +1,38 @@
+package handler
+
+import (
+	"time"
+
+	"github.com/micro/blog/audit/recorder"
+	"go-micro.dev/v5"
+)
+
+type Options struct {
+	// Audit records post changes in the audit log
+	Audit *recorder.Recorder
+	// PublishInterval is how often scheduled posts are checked; 0 disables
+	// the scheduler
+	PublishInterval time.Duration
+	// PostDeleted publishes PostDeleted events to the comments service
+	PostDeleted micro.Event
+}
+
+type Option func(o *Options)
+
+func WithAudit(r *recorder.Recorder) Option {
+	return func(o *Options) {
+		o.Audit = r
+	}
+}
+
+func WithPublishInterval(d time.Duration) Option {
+	return func(o *Options) {
+		o.PublishInterval = d
+	}
+}
+
+func WithPostDeleted(e micro.Event) Option {
+	return func(o *Options) {
+		o.PostDeleted = e
+	}
+}
@@ -3,55 +3,104 @@ package handler
 import (
 	"context"
 	"encoding/json"
+	"log"
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	pb "github.com/micro/blog/posts/proto"
+	"go-micro.dev/v5"
+	"go-micro.dev/v5/errors"
 	"go-micro.dev/v5/store"
 )
 
-type Handler struct{}
+type Handler struct {
+	opts Options
+}
 
-func New() *Handler {
-	return &Handler{}
+func New(opts ...Option) *Handler {
+	options := Options{
+		PublishInterval: 30 * time.Second,
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+	h := &Handler{opts: options}
+	// Render posts written before Markdown support
+	renderExisting()
+	if options.PublishInterval > 0 {
+		go h.publishScheduled()
+	}
+	return h
 }
 
 var postStore = store.DefaultStore
 
+// linkPreviewFor returns a preview of the first link in content, keeping
+// current if the link hasn't changed. Fetching one goes over the network, so
+// callers don't hold mu.
+func linkPreviewFor(content string, current *pb.LinkPreview) *pb.LinkPreview {
+	url := extractFirstURL(content)
+	if url == "" {
+		return nil
+	}
+	if current != nil && current.Url == url {
+		return current
+	}
+	title, desc, image, err := fetchLinkPreview(url)
+	if err != nil {
+		return nil
+	}
+	return &pb.LinkPreview{
+		Url:         url,
+		Title:       title,
+		Description: desc,
+		Image:       image,
+	}
+}
+
+// List pages hold defaultListLimit posts unless asked for more, up to
+// maxListLimit
+const (
+	defaultListLimit = 20
+	maxListLimit     = 100
+)
+
 func (h *Handler) Create(ctx context.Context, req *pb.CreateRequest, res *pb.CreateResponse) error {
 	post := &pb.Post{
-		Id:         uuid.New().String(),
-		Title:      req.Title,
-		Content:    req.Content,
-		AuthorId:   req.AuthorId,
-		AuthorName: req.AuthorName,
-		CreatedAt:  time.Now().Unix(),
-		UpdatedAt:  time.Now().Unix(),
+		Id:           uuid.New().String(),
+		Title:        req.Title,
+		Content:      req.Content,
+		AuthorId:     req.AuthorId,
+		AuthorName:   req.AuthorName,
+		AuthorHandle: req.AuthorHandle,
+		Status:       statusDraft,
+		CreatedAt:    time.Now().Unix(),
+		UpdatedAt:    time.Now().Unix(),
+	}
+	status := req.Status
+	if status == "" {
+		status = statusPublished
+	}
+	if err := setStatus("posts.Create", post, status, req.PublishAt, time.Now()); err != nil {
+		return err
 	}
 
 	// Extract first URL and fetch link preview
-	url := extractFirstURL(req.Content)
-	if url != "" {
-		title, desc, image, err := fetchLinkPreview(url)
-		if err == nil {
-			post.LinkPreview = &pb.LinkPreview{
-				Url:         url,
-				Title:       title,
-				Description: desc,
-				Image:       image,
-			}
-		}
+	post.LinkPreview = linkPreviewFor(post.Content, nil)
+	post.ContentHtml = renderContent(post.Content)
+
+	// Save to store, with the post as it was written as revision 1
+	mu.Lock()
+	_, err := savePost(post, nil, req.AuthorId, 0)
+	mu.Unlock()
+	if err != nil {
+		return errors.InternalServerError("posts.Create", "failed to save post")
 	}
-
+	h.record(ctx, "post.create", post)
 	res.Post = post
 
-	// Save to store
-	b, err := json.Marshal(post)
-	if err == nil {
-		_ = postStore.Write(&store.Record{Key: "post-" + post.Id, Value: b})
-	}
-
 	return nil
 }
 
@@ -60,8 +109,12 @@ func (h *Handler) Read(ctx context.Context, req *pb.ReadRequest, res *pb.ReadRes
 	if err == nil && len(rec) > 0 {
 		var post pb.Post
 		if err := json.Unmarshal(rec[0].Value, &post); err == nil {
-			res.Post = &post
-			return nil
+			// Posts that aren't published read as missing to anyone but the author
+			if id, _ := caller(ctx); visible(&post, id, time.Now()) {
+				post.Status = statusOf(&post, time.Now())
+				res.Post = &post
+				return nil
+			}
 		}
 	}
 	res.Post = nil
@@ -69,53 +122,167 @@ func (h *Handler) Read(ctx context.Context, req *pb.ReadRequest, res *pb.ReadRes
 }
 
 func (h *Handler) Update(ctx context.Context, req *pb.UpdateRequest, res *pb.UpdateResponse) error {
-	rec, err := postStore.Read("post-" + req.Id)
-	if err != nil || len(rec) == 0 {
-		res.Post = nil
-		return nil
+	post, err := revisionPost(ctx, "posts.Update", req.Id)
+	if err != nil {
+		return err
 	}
-	var post pb.Post
-	if err := json.Unmarshal(rec[0].Value, &post); err != nil {
-		res.Post = nil
-		return nil
+	preview := post.LinkPreview
+	if req.Content != nil {
+		preview = linkPreviewFor(*req.Content, post.LinkPreview)
 	}
-	post.Title = req.Title
-	post.Content = req.Content
-	b, err := json.Marshal(&post)
-	if err == nil {
-		_ = postStore.Write(&store.Record{Key: "post-" + post.Id, Value: b})
+
+	mu.Lock()
+	defer mu.Unlock()
+	// Re-read the post so a publish or edit since isn't overwritten
+	post, err = revisionPost(ctx, "posts.Update", req.Id)
+	if err != nil {
+		return err
 	}
-	res.Post = &post
+	previous := revisionOf(post)
+	if req.Status != "" || req.PublishAt != 0 {
+		if err := setStatus("posts.Update", post, req.Status, req.PublishAt, time.Now()); err != nil {
+			return err
+		}
+	}
+	if req.Title != nil {
+		post.Title = *req.Title
+	}
+	if req.Content != nil {
+		post.Content = *req.Content
+		post.LinkPreview = preview
+	}
+	if strings.TrimSpace(post.Title) == "" && strings.TrimSpace(post.Content) == "" {
+		return errors.BadRequest("posts.Update", "a post needs a title or content")
+	}
+	post.UpdatedAt = time.Now().Unix()
+
+	// Every edit is kept as a revision; a status change alone isn't one
+	if post.Title == previous.Title && post.Content == previous.Content {
+		if err := writePost(post); err != nil {
+			return errors.InternalServerError("posts.Update", "failed to save post")
+		}
+	} else {
+		post.ContentHtml = renderContent(post.Content)
+		editorID, _ := caller(ctx)
+		if _, err := savePost(post, previous, editorID, 0); err != nil {
+			return errors.InternalServerError("posts.Update", "failed to save post")
+		}
+	}
+	h.record(ctx, "post.update", post)
+	res.Post = post
 	return nil
 }
 
 func (h *Handler) Delete(ctx context.Context, req *pb.DeleteRequest, res *pb.DeleteResponse) error {
-	_ = postStore.Delete("post-" + req.Id)
+	post, err := deletePost(ctx, req.Id)
+	if err != nil {
+		return err
+	}
+	publishDeleted(ctx, h.opts.PostDeleted, post.Id)
+	h.record(ctx, "post.delete", post)
 	return nil
 }
 
+// deletePost removes a post and its revisions under mu, so an edit can't
+// bring it back
+func deletePost(ctx context.Context, id string) (*pb.Post, error) {
+	mu.Lock()
+	defer mu.Unlock()
+	rec, err := postStore.Read("post-" + id)
+	if err != nil || len(rec) == 0 {
+		return nil, errors.NotFound("posts.Delete", "post not found")
+	}
+	var post pb.Post
+	if err := json.Unmarshal(rec[0].Value, &post); err != nil {
+		return nil, errors.InternalServerError("posts.Delete", "failed to decode post")
+	}
+	if err := authorize(ctx, "posts.Delete", post.AuthorId); err != nil {
+		return nil, err
+	}
+	if err := postStore.Delete("post-" + id); err != nil {
+		return nil, errors.InternalServerError("posts.Delete", "failed to delete post")
+	}
+	if err := deleteRevisions(id); err != nil {
+		log.Printf("Failed to delete revisions of post %s: %v", id, err)
+	}
+	return &post, nil
+}
+
+// publishDeleted tells the comments service a post is gone, so it deletes
+// the comments on it. Callers don't hold mu, as publishing goes over the
+// network.
+func publishDeleted(ctx context.Context, e micro.Event, postID string) {
+	if e == nil {
+		return
+	}
+	if err := e.Publish(ctx, &pb.PostDeleted{PostId: postID}); err != nil {
+		log.Printf("Failed to publish the deletion of post %s: %v", postID, err)
+	}
+}
+
 func (h *Handler) List(ctx context.Context, req *pb.ListRequest, res *pb.ListResponse) error {
+	var cursor *postCursor
+	if req.Cursor != "" {
+		c, err := parsePostCursor(req.Cursor)
+		if err != nil {
+			return errors.BadRequest("posts.List", "invalid cursor")
+		}
+		cursor = &c
+	}
+	limit := int(req.Limit)
+	if limit <= 0 {
+		limit = defaultListLimit
+	}
+	if limit > maxListLimit {
+		limit = maxListLimit
+	}
+	excluded := map[string]bool{}
+	for _, id := range req.ExcludeAuthorIds {
+		excluded[id] = true
+	}
+	callerID, _ := caller(ctx)
+	now := time.Now()
+
 	rec, err := postStore.Read("post-", store.ReadPrefix())
-	if err == nil && len(rec) > 0 {
-		var loadedPosts []*pb.Post
-		for _, r := range rec {
-			var p pb.Post
-			if err := json.Unmarshal(r.Value, &p); err == nil {
-				loadedPosts = append(loadedPosts, &p)
-			}
+	if err != nil {
+		return errors.InternalServerError("posts.List", "failed to read posts")
+	}
+	var posts []*pb.Post
+	for _, r := range rec {
+		var p pb.Post
+		if err := json.Unmarshal(r.Value, &p); err != nil {
+			continue
 		}
-		// Sort posts by CreatedAt descending (reverse chronological)
-		if len(loadedPosts) > 1 {
-			sort.Slice(loadedPosts, func(i, j int) bool {
-				return loadedPosts[i].CreatedAt > loadedPosts[j].CreatedAt
-			})
+		if req.AuthorId != "" && p.AuthorId != req.AuthorId {
+			continue
 		}
-		res.Posts = loadedPosts
-		res.Total = int32(len(loadedPosts))
-		return nil
+		if excluded[p.AuthorId] || !visible(&p, callerID, now) {
+			continue
+		}
+		p.Status = statusOf(&p, now)
+		if req.Status == "" || p.Status == req.Status {
+			posts = append(posts, &p)
+		}
+	}
+	sortNewestFirst(posts)
+	res.Total = int32(len(posts))
+
+	if cursor != nil {
+		start := sort.Search(len(posts), func(i int) bool { return cursor.before(posts[i]) })
+		posts = posts[start:]
+	} else if req.Page > 1 {
+		start := (int(req.Page) - 1) * limit
+		if start > len(posts) {
+			start = len(posts)
+		}
+		posts = posts[start:]
 	}
-	res.Posts = nil
-	res.Total = 0
+	if len(posts) > limit {
+		posts = posts[:limit]
+		last := posts[limit-1]
+		res.NextCursor = postCursor{at: postTime(last), id: last.Id}.String()
+	}
+	res.Posts = posts
 	return nil
 }
 
@@ -124,6 +291,8 @@ func (h *Handler) TagPost(ctx context.Context, req *pb.TagPostRequest, res *pb.T
 		return nil
 	}
 
+	mu.Lock()
+	defer mu.Unlock()
 	rec, err := postStore.Read("post-" + req.PostId)
 	if err != nil || len(rec) == 0 {
 		return nil
@@ -167,6 +336,8 @@ func (h *Handler) UntagPost(ctx context.Context, req *pb.UntagPostRequest, res *
 		return nil
 	}
 
+	mu.Lock()
+	defer mu.Unlock()
 	rec, err := postStore.Read("post-" + req.PostId)
 	if err != nil || len(rec) == 0 {
 		return nil
@@ -218,7 +389,10 @@ func (h *Handler) ListTags(ctx context.Context, req *pb.ListTagsRequest, res *pb
 			return nil
 		}
 
-		res.Tags = post.Tags
+		// The tags of unpublished posts are as private as the posts
+		if id, _ := caller(ctx); visible(&post, id, time.Now()) {
+			res.Tags = post.Tags
+		}
 		return nil
 	}
 
@@ -230,9 +404,10 @@ func (h *Handler) ListTags(ctx context.Context, req *pb.ListTagsRequest, res *pb
 		return nil
 	}
 
+	now := time.Now()
 	for _, r := range rec {
 		var post pb.Post
-		if err := json.Unmarshal(r.Value, &post); err == nil {
+		if err := json.Unmarshal(r.Value, &post); err == nil && isPublished(&post, now) {
 			for _, tag := range post.Tags {
 				allTags[tag] = struct{}{}
 			}
@@ -0,0 +1,289 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	pb "github.com/micro/blog/posts/proto"
+	"go-micro.dev/v5/errors"
+)
+
+func TestListLimit(t *testing.T) {
+	h := newTestHandler(t)
+	createPosts(t, h, "jane", maxListLimit+5)
+
+	tests := []struct {
+		limit int32
+		want  int
+	}{
+		{0, defaultListLimit},
+		{-1, defaultListLimit},
+		{7, 7},
+		{maxListLimit + 1, maxListLimit},
+	}
+	for _, tt := range tests {
+		var rsp pb.ListResponse
+		if err := h.List(context.Background(), &pb.ListRequest{Limit: tt.limit}, &rsp); err != nil {
+			t.Fatalf("List(limit %d): %v", tt.limit, err)
+		}
+		if len(rsp.Posts) != tt.want || rsp.NextCursor == "" {
+			t.Errorf("List(limit %d) returned %d posts (next cursor %q), want %d and a cursor", tt.limit, len(rsp.Posts), rsp.NextCursor, tt.want)
+		}
+		if rsp.Total != maxListLimit+5 {
+			t.Errorf("List(limit %d) total = %d, want %d", tt.limit, rsp.Total, maxListLimit+5)
+		}
+	}
+}
+
+func TestListCursor(t *testing.T) {
+	h := newTestHandler(t)
+	createPosts(t, h, "jane", 45)
+
+	seen := map[string]bool{}
+	req := &pb.ListRequest{}
+	for pages := 1; ; pages++ {
+		var rsp pb.ListResponse
+		if err := h.List(context.Background(), req, &rsp); err != nil {
+			t.Fatalf("List: %v", err)
+		}
+		for _, p := range rsp.Posts {
+			if seen[p.Id] {
+				t.Fatalf("post %s listed twice", p.Id)
+			}
+			seen[p.Id] = true
+		}
+		if rsp.NextCursor == "" {
+			if pages != 3 {
+				t.Errorf("listed %d pages, want 3", pages)
+			}
+			break
+		}
+		req.Cursor = rsp.NextCursor
+	}
+	if len(seen) != 45 {
+		t.Errorf("listed %d posts, want 45", len(seen))
+	}
+}
+
+func TestUpdateStatusOnly(t *testing.T) {
+	h := newTestHandler(t)
+	var created pb.CreateResponse
+	req := &pb.CreateRequest{Title: "Draft", Content: "Not *yet*", AuthorId: "jane", Status: statusDraft}
+	if err := h.Create(as("jane"), req, &created); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	id := created.Post.Id
+
+	// Publishing leaves the title and content alone and isn't a revision
+	var updated pb.UpdateResponse
+	if err := h.Update(as("jane"), &pb.UpdateRequest{Id: id, Status: statusPublished}, &updated); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	p := updated.Post
+	if p.Status != statusPublished || p.Title != "Draft" || p.Content != "Not *yet*" || p.ContentHtml == "" {
+		t.Errorf("publishing changed the post: %+v", p)
+	}
+	revs, err := readRevisions(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(revs) != 1 {
+		t.Errorf("%d revisions after a status change, want 1", len(revs))
+	}
+
+	// An edit of one field keeps the other and is a revision
+	title := "Published"
+	if err := h.Update(as("jane"), &pb.UpdateRequest{Id: id, Title: &title}, &updated); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if updated.Post.Title != "Published" || updated.Post.Content != "Not *yet*" {
+		t.Errorf("editing the title gave %+v", updated.Post)
+	}
+	if revs, _ := readRevisions(id); len(revs) != 2 {
+		t.Errorf("%d revisions after an edit, want 2", len(revs))
+	}
+}
+
+func TestUpdateRejectsEmptyPost(t *testing.T) {
+	h := newTestHandler(t)
+	var created pb.CreateResponse
+	if err := h.Create(as("jane"), &pb.CreateRequest{Title: "Title", AuthorId: "jane"}, &created); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	empty := " "
+	err := h.Update(as("jane"), &pb.UpdateRequest{Id: created.Post.Id, Title: &empty}, &pb.UpdateResponse{})
+	if errors.FromError(err).Code != http.StatusBadRequest {
+		t.Errorf("Update leaving the post empty = %v, want 400", err)
+	}
+	var read pb.ReadResponse
+	if err := h.Read(as("jane"), &pb.ReadRequest{Id: created.Post.Id}, &read); err != nil || read.Post.Title != "Title" {
+		t.Errorf("post after a rejected update: %+v (%v)", read.Post, err)
+	}
+}
+
+func TestListTagsOfDraft(t *testing.T) {
+	h := newTestHandler(t)
+	var created pb.CreateResponse
+	if err := h.Create(as("jane"), &pb.CreateRequest{Title: "Draft", AuthorId: "jane", Status: statusDraft}, &created); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	id := created.Post.Id
+	if err := h.TagPost(as("jane"), &pb.TagPostRequest{PostId: id, Tag: "secret"}, &pb.TagPostResponse{}); err != nil {
+		t.Fatalf("TagPost: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want int
+	}{
+		{"author", as("jane"), 1},
+		{"another user", as("mallory"), 0},
+		{"anonymous", context.Background(), 0},
+	}
+	for _, tt := range tests {
+		var rsp pb.ListTagsResponse
+		if err := h.ListTags(tt.ctx, &pb.ListTagsRequest{PostId: id}, &rsp); err != nil {
+			t.Fatalf("ListTags: %v", err)
+		}
+		if len(rsp.Tags) != tt.want {
+			t.Errorf("ListTags of a draft for the %s = %q, want %d tags", tt.name, rsp.Tags, tt.want)
+		}
+	}
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	tests := []struct {
+		name, from, to, want string
+	}{
+		{"same", "a\nb\n", "a\nb\n", ""},
+		{"trailing newline", "a\nb", "a\nb\n", ""},
+		{"insert", "a\nb\n", "a\nx\nb\n", "@@ -1,2 +1,3 @@\n a\n+x\n b\n"},
+		{"delete", "a\nb\nc\n", "a\nc\n", "@@ -1,3 +1,2 @@\n a\n-b\n c\n"},
+		{"change", "a\nb\nc\n", "a\nB\nc\n", "@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n"},
+		{"from empty", "", "a\nb\n", "@@ -0,0 +1,2 @@\n+a\n+b\n"},
+		{"to empty", "a\n", "", "@@ -1 +0,0 @@\n-a\n"},
+		{"append", "a\nb\nc\nd\ne\n", "a\nb\nc\nd\ne\nf\n", "@@ -3,3 +3,4 @@\n c\n d\n e\n+f\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := tt.want
+			if want != "" {
+				want = "--- old\n+++ new\n" + want
+			}
+			if got := unifiedDiff("old", "new", tt.from, tt.to); got != want {
+				t.Errorf("unifiedDiff(%q, %q) =\n%s\nwant\n%s", tt.from, tt.to, got, want)
+			}
+		})
+	}
+}
+
+func TestUnifiedDiffHunks(t *testing.T) {
+	var from, to strings.Builder
+	for i := 1; i <= 20; i++ {
+		fmt.Fprintf(&from, "line %d\n", i)
+		switch i {
+		case 2:
+			to.WriteString("changed 2\n")
+		case 18:
+			// deleted
+		default:
+			fmt.Fprintf(&to, "line %d\n", i)
+		}
+	}
+	// Changes further apart than twice the context are separate hunks, and
+	// the second is numbered after the first's change on each side
+	var headers []string
+	for _, l := range strings.Split(unifiedDiff("old", "new", from.String(), to.String()), "\n") {
+		if strings.HasPrefix(l, "@@") {
+			headers = append(headers, l)
+		}
+	}
+	want := []string{"@@ -1,5 +1,5 @@", "@@ -15,6 +15,5 @@"}
+	if strings.Join(headers, "|") != strings.Join(want, "|") {
+		t.Errorf("hunk headers = %q, want %q", headers, want)
+	}
+}
+
+func TestHunkRange(t *testing.T) {
+	tests := []struct {
+		start, count int
+		want         string
+	}{
+		{1, 0, "0,0"},
+		{5, 0, "4,0"},
+		{3, 1, "3"},
+		{3, 4, "3,4"},
+	}
+	for _, tt := range tests {
+		if got := hunkRange(tt.start, tt.count); got != tt.want {
+			t.Errorf("hunkRange(%d, %d) = %q, want %q", tt.start, tt.count, got, tt.want)
+		}
+	}
+}
+
+func TestRestoreRevision(t *testing.T) {
+	h := newTestHandler(t)
+	var created pb.CreateResponse
+	if err := h.Create(as("jane"), &pb.CreateRequest{Title: "First", Content: "one", AuthorId: "jane"}, &created); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	id := created.Post.Id
+	for _, content := range []string{"two", "three"} {
+		if err := h.Update(as("jane"), &pb.UpdateRequest{Id: id, Content: &content}, &pb.UpdateResponse{}); err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+	}
+
+	var diff pb.DiffRevisionsResponse
+	if err := h.DiffRevisions(as("jane"), &pb.DiffRevisionsRequest{PostId: id}, &diff); err != nil {
+		t.Fatalf("DiffRevisions: %v", err)
+	}
+	if diff.From != 2 || diff.To != 3 || !strings.Contains(diff.Diff, "-two\n+three\n") {
+		t.Errorf("DiffRevisions = %d..%d %q, want 2..3 replacing two with three", diff.From, diff.To, diff.Diff)
+	}
+
+	var restored pb.RestoreRevisionResponse
+	if err := h.RestoreRevision(as("jane"), &pb.RestoreRevisionRequest{PostId: id, Number: 1}, &restored); err != nil {
+		t.Fatalf("RestoreRevision: %v", err)
+	}
+	if restored.Post.Title != "First" || restored.Post.Content != "one" {
+		t.Errorf("restored post = %q %q, want the first version", restored.Post.Title, restored.Post.Content)
+	}
+	if rev := restored.Revision; rev.Number != 4 || rev.RestoredFrom != 1 || rev.Content != "one" || rev.EditorId != "jane" {
+		t.Errorf("restore revision = %+v, want number 4 restored from 1", rev)
+	}
+
+	var list pb.ListRevisionsResponse
+	if err := h.ListRevisions(as("jane"), &pb.ListRevisionsRequest{PostId: id}, &list); err != nil {
+		t.Fatalf("ListRevisions: %v", err)
+	}
+	var got []string
+	for i, rev := range list.Revisions {
+		if rev.Number != int32(i+1) {
+			t.Errorf("revision %d is numbered %d", i+1, rev.Number)
+		}
+		got = append(got, rev.Content)
+	}
+	if strings.Join(got, ",") != "one,two,three,one" {
+		t.Errorf("revisions = %q, want one, two, three, one", got)
+	}
+	var read pb.ReadResponse
+	if err := h.Read(as("jane"), &pb.ReadRequest{Id: id}, &read); err != nil || read.Post.Content != "one" {
+		t.Errorf("post after restore: %+v (%v)", read.Post, err)
+	}
+
+	// Only the author and moderators restore, and only revisions that exist
+	err := h.RestoreRevision(as("mallory"), &pb.RestoreRevisionRequest{PostId: id, Number: 2}, &pb.RestoreRevisionResponse{})
+	if errors.FromError(err).Code != http.StatusForbidden {
+		t.Errorf("RestoreRevision by another user = %v, want 403", err)
+	}
+	err = h.RestoreRevision(as("jane"), &pb.RestoreRevisionRequest{PostId: id, Number: 9}, &pb.RestoreRevisionResponse{})
+	if errors.FromError(err).Code != http.StatusNotFound {
+		t.Errorf("RestoreRevision of a missing revision = %v, want 404", err)
+	}
+}
@@ -0,0 +1,253 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	pb "github.com/micro/blog/posts/proto"
+	"go-micro.dev/v5/errors"
+	"go-micro.dev/v5/store"
+)
+
+// mu serializes saving a revision with the post it describes, so revision
+// numbers are unique and the latest revision matches the post. Edits hold
+// it from reading the post to writing it, as does the scheduler while
+// publishing, so none overwrites another.
+var mu sync.Mutex
+
+// revisionKey orders a post's revisions by number
+func revisionKey(postID string, number int32) string {
+	return fmt.Sprintf("revision-%s-%06d", postID, number)
+}
+
+// readRevisions returns a post's revisions, oldest first
+func readRevisions(postID string) ([]*pb.Revision, error) {
+	rec, err := postStore.Read("revision-"+postID+"-", store.ReadPrefix())
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(rec, func(i, j int) bool { return rec[i].Key < rec[j].Key })
+	var revs []*pb.Revision
+	for _, r := range rec {
+		var rev pb.Revision
+		if err := json.Unmarshal(r.Value, &rev); err == nil {
+			revs = append(revs, &rev)
+		}
+	}
+	return revs, nil
+}
+
+// saveRevision numbers a revision after the post's latest and stores it.
+// Callers hold mu.
+func saveRevision(rev *pb.Revision) error {
+	revs, err := readRevisions(rev.PostId)
+	if err != nil {
+		return err
+	}
+	rev.Number = 1
+	if len(revs) > 0 {
+		rev.Number = revs[len(revs)-1].Number + 1
+	}
+	b, err := json.Marshal(rev)
+	if err != nil {
+		return err
+	}
+	return postStore.Write(&store.Record{Key: revisionKey(rev.PostId, rev.Number), Value: b})
+}
+
+// revisionOf returns the version of a post as it stands, credited to its author
+func revisionOf(post *pb.Post) *pb.Revision {
+	return &pb.Revision{
+		PostId:    post.Id,
+		Title:     post.Title,
+		Content:   post.Content,
+		EditorId:  post.AuthorId,
+		CreatedAt: post.UpdatedAt,
+	}
+}
+
+// savePost stores a post along with a revision of its title and content.
+// Posts written before revisions existed get their previous version saved
+// first, as revision 1. Callers hold mu.
+func savePost(post *pb.Post, previous *pb.Revision, editorID string, restoredFrom int32) (*pb.Revision, error) {
+	if previous != nil {
+		revs, err := readRevisions(post.Id)
+		if err != nil {
+			return nil, err
+		}
+		if len(revs) == 0 {
+			if err := saveRevision(previous); err != nil {
+				return nil, err
+			}
+		}
+	}
+	rev := &pb.Revision{
+		PostId:       post.Id,
+		Title:        post.Title,
+		Content:      post.Content,
+		EditorId:     editorID,
+		CreatedAt:    post.UpdatedAt,
+		RestoredFrom: restoredFrom,
+	}
+	if err := saveRevision(rev); err != nil {
+		return nil, err
+	}
+	b, err := json.Marshal(post)
+	if err != nil {
+		return nil, err
+	}
+	if err := postStore.Write(&store.Record{Key: "post-" + post.Id, Value: b}); err != nil {
+		return nil, err
+	}
+	return rev, nil
+}
+
+// writePost stores a post whose title and content haven't changed, so
+// without a new revision. Callers hold mu.
+func writePost(post *pb.Post) error {
+	b, err := json.Marshal(post)
+	if err != nil {
+		return err
+	}
+	return postStore.Write(&store.Record{Key: "post-" + post.Id, Value: b})
+}
+
+// deleteRevisions removes every revision of a post
+func deleteRevisions(postID string) error {
+	rec, err := postStore.Read("revision-"+postID+"-", store.ReadPrefix())
+	if err != nil {
+		return err
+	}
+	for _, r := range rec {
+		if err := postStore.Delete(r.Key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// revisionPost reads a post for an edit or one of the revision RPCs, which
+// only its author and moderators may use
+func revisionPost(ctx context.Context, method, postID string) (*pb.Post, error) {
+	rec, err := postStore.Read("post-" + postID)
+	if err != nil || len(rec) == 0 {
+		return nil, errors.NotFound(method, "post not found")
+	}
+	var post pb.Post
+	if err := json.Unmarshal(rec[0].Value, &post); err != nil {
+		return nil, errors.InternalServerError(method, "failed to decode post")
+	}
+	if err := authorize(ctx, method, post.AuthorId); err != nil {
+		return nil, err
+	}
+	return &post, nil
+}
+
+// findRevision returns the revision with the given number
+func findRevision(revs []*pb.Revision, number int32) *pb.Revision {
+	for _, rev := range revs {
+		if rev.Number == number {
+			return rev
+		}
+	}
+	return nil
+}
+
+// revisionText is what a diff compares: the title, a blank line and the content
+func revisionText(rev *pb.Revision) string {
+	if rev == nil {
+		return ""
+	}
+	return rev.Title + "\n\n" + rev.Content
+}
+
+func (h *Handler) ListRevisions(ctx context.Context, req *pb.ListRevisionsRequest, rsp *pb.ListRevisionsResponse) error {
+	if _, err := revisionPost(ctx, "posts.ListRevisions", req.PostId); err != nil {
+		return err
+	}
+	revs, err := readRevisions(req.PostId)
+	if err != nil {
+		return errors.InternalServerError("posts.ListRevisions", "failed to read revisions")
+	}
+	rsp.Revisions = revs
+	return nil
+}
+
+func (h *Handler) DiffRevisions(ctx context.Context, req *pb.DiffRevisionsRequest, rsp *pb.DiffRevisionsResponse) error {
+	if _, err := revisionPost(ctx, "posts.DiffRevisions", req.PostId); err != nil {
+		return err
+	}
+	revs, err := readRevisions(req.PostId)
+	if err != nil {
+		return errors.InternalServerError("posts.DiffRevisions", "failed to read revisions")
+	}
+	if len(revs) == 0 {
+		return errors.NotFound("posts.DiffRevisions", "post has no revisions")
+	}
+	to := req.To
+	if to == 0 {
+		to = revs[len(revs)-1].Number
+	}
+	from := req.From
+	if from == 0 {
+		from = to - 1
+	}
+	toRev := findRevision(revs, to)
+	if toRev == nil {
+		return errors.NotFound("posts.DiffRevisions", "revision %d not found", to)
+	}
+	// Revision 1 is compared with an empty post
+	fromRev := findRevision(revs, from)
+	if fromRev == nil && from != 0 {
+		return errors.NotFound("posts.DiffRevisions", "revision %d not found", from)
+	}
+	rsp.From = from
+	rsp.To = to
+	rsp.Diff = unifiedDiff("revision "+strconv.Itoa(int(from)), "revision "+strconv.Itoa(int(to)), revisionText(fromRev), revisionText(toRev))
+	return nil
+}
+
+func (h *Handler) RestoreRevision(ctx context.Context, req *pb.RestoreRevisionRequest, rsp *pb.RestoreRevisionResponse) error {
+	post, err := revisionPost(ctx, "posts.RestoreRevision", req.PostId)
+	if err != nil {
+		return err
+	}
+	revs, err := readRevisions(req.PostId)
+	if err != nil {
+		return errors.InternalServerError("posts.RestoreRevision", "failed to read revisions")
+	}
+	old := findRevision(revs, req.Number)
+	if old == nil {
+		return errors.NotFound("posts.RestoreRevision", "revision %d not found", req.Number)
+	}
+	preview := linkPreviewFor(old.Content, post.LinkPreview)
+
+	mu.Lock()
+	defer mu.Unlock()
+	// Re-read the post so a publish or edit since isn't overwritten
+	post, err = revisionPost(ctx, "posts.RestoreRevision", req.PostId)
+	if err != nil {
+		return err
+	}
+	previous := revisionOf(post)
+	post.Title = old.Title
+	post.Content = old.Content
+	post.UpdatedAt = time.Now().Unix()
+	post.LinkPreview = preview
+	post.ContentHtml = renderContent(post.Content)
+
+	editorID, _ := caller(ctx)
+	rev, err := savePost(post, previous, editorID, old.Number)
+	if err != nil {
+		return errors.InternalServerError("posts.RestoreRevision", "failed to save post")
+	}
+	h.record(ctx, "post.restore", post)
+	rsp.Post = post
+	rsp.Revision = rev
+	return nil
+}
@@ -0,0 +1,138 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	pb "github.com/micro/blog/posts/proto"
+	"go-micro.dev/v5/errors"
+	"go-micro.dev/v5/store"
+)
+
+// Post statuses. Posts written before statuses existed have none and are
+// published.
+const (
+	statusDraft     = "draft"
+	statusPublished = "published"
+	statusScheduled = "scheduled"
+	statusArchived  = "archived"
+)
+
+// isPublished reports whether a post is public. A scheduled post is public
+// from its publish time, even before the scheduler gets to it.
+func isPublished(p *pb.Post, now time.Time) bool {
+	switch p.Status {
+	case "", statusPublished:
+		return true
+	case statusScheduled:
+		return p.PublishAt <= now.Unix()
+	}
+	return false
+}
+
+// statusOf returns a post's status as readers see it
+func statusOf(p *pb.Post, now time.Time) string {
+	if isPublished(p, now) {
+		return statusPublished
+	}
+	return p.Status
+}
+
+// visible reports whether a caller can see a post. Posts that aren't
+// published are only visible to their author.
+func visible(p *pb.Post, callerID string, now time.Time) bool {
+	return isPublished(p, now) || (callerID != "" && callerID == p.AuthorId)
+}
+
+// postTime orders posts: when they were published, or created if they
+// haven't been
+func postTime(p *pb.Post) int64 {
+	if p.PublishAt != 0 {
+		return p.PublishAt
+	}
+	return p.CreatedAt
+}
+
+// setStatus moves a post to a status. Publishing sets the publish time to
+// now unless the post is already public; scheduling needs a time in the future.
+func setStatus(method string, p *pb.Post, status string, publishAt int64, now time.Time) error {
+	if publishAt != 0 && status != statusScheduled {
+		return errors.BadRequest(method, "publish_at is only for scheduled posts")
+	}
+	switch status {
+	case statusPublished:
+		if !isPublished(p, now) {
+			p.PublishAt = now.Unix()
+		} else if p.PublishAt == 0 {
+			p.PublishAt = p.CreatedAt
+		}
+	case statusScheduled:
+		if publishAt <= now.Unix() {
+			return errors.BadRequest(method, "publish_at must be in the future")
+		}
+		p.PublishAt = publishAt
+	case statusDraft:
+		p.PublishAt = 0
+	case statusArchived:
+	default:
+		return errors.BadRequest(method, "status must be draft, published, scheduled or archived")
+	}
+	p.Status = status
+	return nil
+}
+
+// publishScheduled publishes scheduled posts when their time comes. The
+// schedule is kept with the posts, so posts that came due while the service
+// was down are published when it starts.
+func (h *Handler) publishScheduled() {
+	h.publishDue()
+	for range time.Tick(h.opts.PublishInterval) {
+		h.publishDue()
+	}
+}
+
+func (h *Handler) publishDue() {
+	rec, err := postStore.Read("post-", store.ReadPrefix())
+	if err != nil {
+		log.Printf("Failed to read scheduled posts: %v", err)
+		return
+	}
+	now := time.Now()
+	for _, r := range rec {
+		var post pb.Post
+		if err := json.Unmarshal(r.Value, &post); err != nil || post.Status != statusScheduled || !isPublished(&post, now) {
+			continue
+		}
+		if published, err := publishPost(r.Key, now); err != nil {
+			log.Printf("Failed to publish post %s: %v", post.Id, err)
+		} else if published != nil {
+			h.record(context.Background(), "post.publish", published)
+		}
+	}
+}
+
+// publishPost marks a scheduled post that has come due as published. It
+// returns nil if the post changed since it was listed.
+func publishPost(key string, now time.Time) (*pb.Post, error) {
+	mu.Lock()
+	defer mu.Unlock()
+	rec, err := postStore.Read(key)
+	if err != nil || len(rec) == 0 {
+		return nil, nil
+	}
+	var post pb.Post
+	if err := json.Unmarshal(rec[0].Value, &post); err != nil || post.Status != statusScheduled || !isPublished(&post, now) {
+		return nil, nil
+	}
+	post.Status = statusPublished
+	b, err := json.Marshal(&post)
+	if err != nil {
+		return nil, err
+	}
+	if err := postStore.Write(&store.Record{Key: key, Value: b}); err != nil {
+		return nil, err
+	}
+	return &post, nil
+}
@@ -1,9 +1,13 @@
 package main
 
 import (
+	"log"
+
+	"github.com/micro/blog/audit/recorder"
 	"github.com/micro/blog/posts/handler"
 	pb "github.com/micro/blog/posts/proto"
 	"go-micro.dev/v5"
+	"go-micro.dev/v5/server"
 )
 
 func main() {
@@ -11,7 +15,18 @@ func main() {
 		micro.Name("posts"),
 	)
 
-	pb.RegisterPostsHandler(service.Server(), handler.New())
+	// Comments are deleted with their posts
+	deleted := micro.NewEvent("post.deleted", service.Client())
+	pb.RegisterPostsHandler(service.Server(), handler.New(
+		handler.WithAudit(recorder.New("posts", service.Client())),
+		handler.WithPostDeleted(deleted),
+	))
+
+	// Delete or anonymize the posts of deleted accounts
+	accounts := handler.NewAccounts(micro.NewEvent("user.deletion.progress", service.Client()), deleted)
+	if err := micro.RegisterSubscriber("user.deleted", service.Server(), accounts.UserDeleted, server.SubscriberQueue("posts")); err != nil {
+		log.Fatalf("Failed to subscribe to user deletions: %v", err)
+	}
 
 	service.Init()
 
@@ -33,10 +33,15 @@ type PostsService interface {
 	Update(ctx context.Context, in *UpdateRequest, opts ...client.CallOption) (*UpdateResponse, error)
 	Delete(ctx context.Context, in *DeleteRequest, opts ...client.CallOption) (*DeleteResponse, error)
 	List(ctx context.Context, in *ListRequest, opts ...client.CallOption) (*ListResponse, error)
+	Feed(ctx context.Context, in *FeedRequest, opts ...client.CallOption) (*FeedResponse, error)
 	// == Tags ==
 	TagPost(ctx context.Context, in *TagPostRequest, opts ...client.CallOption) (*TagPostResponse, error)
 	UntagPost(ctx context.Context, in *UntagPostRequest, opts ...client.CallOption) (*UntagPostResponse, error)
 	ListTags(ctx context.Context, in *ListTagsRequest, opts ...client.CallOption) (*ListTagsResponse, error)
+	// == Revisions ==
+	ListRevisions(ctx context.Context, in *ListRevisionsRequest, opts ...client.CallOption) (*ListRevisionsResponse, error)
+	DiffRevisions(ctx context.Context, in *DiffRevisionsRequest, opts ...client.CallOption) (*DiffRevisionsResponse, error)
+	RestoreRevision(ctx context.Context, in *RestoreRevisionRequest, opts ...client.CallOption) (*RestoreRevisionResponse, error)
 }
 
 type postsService struct {
@@ -101,6 +106,16 @@ func (c *postsService) List(ctx context.Context, in *ListRequest, opts ...client
 	return out, nil
 }
 
+func (c *postsService) Feed(ctx context.Context, in *FeedRequest, opts ...client.CallOption) (*FeedResponse, error) {
+	req := c.c.NewRequest(c.name, "Posts.Feed", in)
+	out := new(FeedResponse)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *postsService) TagPost(ctx context.Context, in *TagPostRequest, opts ...client.CallOption) (*TagPostResponse, error) {
 	req := c.c.NewRequest(c.name, "Posts.TagPost", in)
 	out := new(TagPostResponse)
@@ -131,6 +146,36 @@ func (c *postsService) ListTags(ctx context.Context, in *ListTagsRequest, opts .
 	return out, nil
 }
 
+func (c *postsService) ListRevisions(ctx context.Context, in *ListRevisionsRequest, opts ...client.CallOption) (*ListRevisionsResponse, error) {
+	req := c.c.NewRequest(c.name, "Posts.ListRevisions", in)
+	out := new(ListRevisionsResponse)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *postsService) DiffRevisions(ctx context.Context, in *DiffRevisionsRequest, opts ...client.CallOption) (*DiffRevisionsResponse, error) {
+	req := c.c.NewRequest(c.name, "Posts.DiffRevisions", in)
+	out := new(DiffRevisionsResponse)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *postsService) RestoreRevision(ctx context.Context, in *RestoreRevisionRequest, opts ...client.CallOption) (*RestoreRevisionResponse, error) {
+	req := c.c.NewRequest(c.name, "Posts.RestoreRevision", in)
+	out := new(RestoreRevisionResponse)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // Server API for Posts service
 
 type PostsHandler interface {
@@ -139,10 +184,15 @@ type PostsHandler interface {
 	Update(context.Context, *UpdateRequest, *UpdateResponse) error
 	Delete(context.Context, *DeleteRequest, *DeleteResponse) error
 	List(context.Context, *ListRequest, *ListResponse) error
+	Feed(context.Context, *FeedRequest, *FeedResponse) error
 	// == Tags ==
 	TagPost(context.Context, *TagPostRequest, *TagPostResponse) error
 	UntagPost(context.Context, *UntagPostRequest, *UntagPostResponse) error
 	ListTags(context.Context, *ListTagsRequest, *ListTagsResponse) error
+	// == Revisions ==
+	ListRevisions(context.Context, *ListRevisionsRequest, *ListRevisionsResponse) error
+	DiffRevisions(context.Context, *DiffRevisionsRequest, *DiffRevisionsResponse) error
+	RestoreRevision(context.Context, *RestoreRevisionRequest, *RestoreRevisionResponse) error
 }
 
 func RegisterPostsHandler(s server.Server, hdlr PostsHandler, opts ...server.HandlerOption) error {
@@ -152,9 +202,13 @@ func RegisterPostsHandler(s server.Server, hdlr PostsHandler, opts ...server.Han
 		Update(ctx context.Context, in *UpdateRequest, out *UpdateResponse) error
 		Delete(ctx context.Context, in *DeleteRequest, out *DeleteResponse) error
 		List(ctx context.Context, in *ListRequest, out *ListResponse) error
+		Feed(ctx context.Context, in *FeedRequest, out *FeedResponse) error
 		TagPost(ctx context.Context, in *TagPostRequest, out *TagPostResponse) error
 		UntagPost(ctx context.Context, in *UntagPostRequest, out *UntagPostResponse) error
 		ListTags(ctx context.Context, in *ListTagsRequest, out *ListTagsResponse) error
+		ListRevisions(ctx context.Context, in *ListRevisionsRequest, out *ListRevisionsResponse) error
+		DiffRevisions(ctx context.Context, in *DiffRevisionsRequest, out *DiffRevisionsResponse) error
+		RestoreRevision(ctx context.Context, in *RestoreRevisionRequest, out *RestoreRevisionResponse) error
 	}
 	type Posts struct {
 		posts
@@ -187,6 +241,10 @@ func (h *postsHandler) List(ctx context.Context, in *ListRequest, out *ListRespo
 	return h.PostsHandler.List(ctx, in, out)
 }
 
+func (h *postsHandler) Feed(ctx context.Context, in *FeedRequest, out *FeedResponse) error {
+	return h.PostsHandler.Feed(ctx, in, out)
+}
+
 func (h *postsHandler) TagPost(ctx context.Context, in *TagPostRequest, out *TagPostResponse) error {
 	return h.PostsHandler.TagPost(ctx, in, out)
 }
@@ -198,3 +256,15 @@ func (h *postsHandler) UntagPost(ctx context.Context, in *UntagPostRequest, out
 func (h *postsHandler) ListTags(ctx context.Context, in *ListTagsRequest, out *ListTagsResponse) error {
 	return h.PostsHandler.ListTags(ctx, in, out)
 }
+
+func (h *postsHandler) ListRevisions(ctx context.Context, in *ListRevisionsRequest, out *ListRevisionsResponse) error {
+	return h.PostsHandler.ListRevisions(ctx, in, out)
+}
+
+func (h *postsHandler) DiffRevisions(ctx context.Context, in *DiffRevisionsRequest, out *DiffRevisionsResponse) error {
+	return h.PostsHandler.DiffRevisions(ctx, in, out)
+}
+
+func (h *postsHandler) RestoreRevision(ctx context.Context, in *RestoreRevisionRequest, out *RestoreRevisionResponse) error {
+	return h.PostsHandler.RestoreRevision(ctx, in, out)
+}
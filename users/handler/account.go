@@ -0,0 +1,214 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/micro/blog/users/mailer"
+	pb "github.com/micro/blog/users/proto"
+	"go-micro.dev/v5/errors"
+	"go-micro.dev/v5/store"
+)
+
+// emailChange is the stored state of a requested email change
+type emailChange struct {
+	UserId    string `json:"user_id"`
+	Email     string `json:"email"`
+	ExpiresAt int64  `json:"expires_at"`
+}
+
+// emailChangeKey is the store key of an email change token, addressed by
+// its hash
+func emailChangeKey(hash string) string {
+	return "emailchange-" + hash
+}
+
+// emailChangeUserKey points at the latest email change token issued to a
+// user
+func emailChangeUserKey(id string) string {
+	return "emailchangeuser-" + id
+}
+
+// cancelEmailChange drops a user's outstanding email change. Callers must
+// hold mu.
+func cancelEmailChange(id string) {
+	if hash := indexOwner(emailChangeUserKey(id)); hash != "" {
+		_ = userStore.Delete(emailChangeKey(hash))
+		_ = userStore.Delete(emailChangeUserKey(id))
+	}
+}
+
+// checkPassword re-authenticates a user. Accounts without a password, such
+// as those created by an external login, have to set one with a password
+// reset first.
+func checkPassword(method, userID, password string) error {
+	hash := readPasswordHash(userID)
+	if hash == "" {
+		return errors.BadRequest(method, "account has no password; use a password reset to set one")
+	}
+	if !checkPasswordHash(password, hash) {
+		return errors.Unauthorized(method, "current password is incorrect")
+	}
+	return nil
+}
+
+func (h *Handler) ChangePassword(ctx context.Context, req *pb.ChangePasswordRequest, rsp *pb.ChangePasswordResponse) error {
+	if req.NewPassword == "" {
+		return errors.BadRequest("users.ChangePassword", "new password required")
+	}
+	if readUser(req.UserId) == nil {
+		return errors.NotFound("users.ChangePassword", "user not found")
+	}
+	if err := checkPassword("users.ChangePassword", req.UserId, req.CurrentPassword); err != nil {
+		return err
+	}
+	pwHash, err := h.hashPassword(req.NewPassword)
+	if err != nil {
+		return errors.InternalServerError("users.ChangePassword", "failed to hash password")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if err := userStore.Write(&store.Record{Key: passwordKey(req.UserId), Value: []byte(pwHash)}); err != nil {
+		return errors.InternalServerError("users.ChangePassword", "failed to save password")
+	}
+	// An outstanding reset link would undo the change
+	if hash := indexOwner(resetUserKey(req.UserId)); hash != "" {
+		_ = userStore.Delete(resetKey(hash))
+		_ = userStore.Delete(resetUserKey(req.UserId))
+	}
+	h.record(ctx, "user.password_change", req.UserId, req.UserId, nil)
+	return nil
+}
+
+func (h *Handler) RequestEmailChange(ctx context.Context, req *pb.RequestEmailChangeRequest, rsp *pb.RequestEmailChangeResponse) error {
+	email := normalizeEmail(req.Email)
+	if email == "" {
+		return errors.BadRequest("users.RequestEmailChange", "email required")
+	}
+	user := readUser(req.UserId)
+	if user == nil {
+		return errors.NotFound("users.RequestEmailChange", "user not found")
+	}
+	if normalizeEmail(user.Email) == email {
+		return errors.BadRequest("users.RequestEmailChange", "that is already your email")
+	}
+	if err := checkPassword("users.RequestEmailChange", user.Id, req.Password); err != nil {
+		return err
+	}
+	token, err := newToken()
+	if err != nil {
+		return errors.InternalServerError("users.RequestEmailChange", "failed to generate token")
+	}
+	hash := hashToken(token)
+	b, _ := json.Marshal(&emailChange{
+		UserId:    user.Id,
+		Email:     email,
+		ExpiresAt: time.Now().Add(h.opts.VerifyTokenTTL).Unix(),
+	})
+
+	mu.Lock()
+	if indexOwner(emailKey(email)) != "" {
+		mu.Unlock()
+		return errors.Conflict("users.RequestEmailChange", "email already registered")
+	}
+	// Only the latest request can be confirmed
+	cancelEmailChange(user.Id)
+	err = userStore.Write(&store.Record{Key: emailChangeKey(hash), Value: b, Expiry: h.opts.VerifyTokenTTL})
+	if err == nil {
+		_ = userStore.Write(&store.Record{Key: emailChangeUserKey(user.Id), Value: []byte(hash), Expiry: h.opts.VerifyTokenTTL})
+	}
+	mu.Unlock()
+	if err != nil {
+		return errors.InternalServerError("users.RequestEmailChange", "failed to save token")
+	}
+
+	msg := &mailer.Message{
+		To:      email,
+		Subject: "Confirm your new email address",
+		Body: fmt.Sprintf("Hi %s,\n\nFollow this link to use this address for your account:\n\n"+
+			"%s/users/me/email/confirm?token=%s\n\nThe link expires in %s. "+
+			"Until then your account keeps its current address.\n",
+			user.Name, h.opts.BaseURL, token, h.opts.VerifyTokenTTL),
+	}
+	if err := h.opts.Mailer.Send(msg); err != nil {
+		log.Printf("Failed to send email change confirmation to %s: %v", email, err)
+		return errors.InternalServerError("users.RequestEmailChange", "failed to send email")
+	}
+	h.record(ctx, "user.email_change_request", user.Id, user.Id, map[string]string{"email": email})
+	return nil
+}
+
+func (h *Handler) ConfirmEmailChange(ctx context.Context, req *pb.ConfirmEmailChangeRequest, rsp *pb.ConfirmEmailChangeResponse) error {
+	if req.Token == "" {
+		return errors.BadRequest("users.ConfirmEmailChange", "invalid or expired token")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	hash := hashToken(req.Token)
+	rec, err := userStore.Read(emailChangeKey(hash))
+	if err != nil || len(rec) == 0 {
+		return errors.BadRequest("users.ConfirmEmailChange", "invalid or expired token")
+	}
+	_ = userStore.Delete(emailChangeKey(hash))
+
+	var change emailChange
+	if err := json.Unmarshal(rec[0].Value, &change); err != nil || time.Now().Unix() > change.ExpiresAt {
+		return errors.BadRequest("users.ConfirmEmailChange", "invalid or expired token")
+	}
+	_ = userStore.Delete(emailChangeUserKey(change.UserId))
+
+	user := readUser(change.UserId)
+	if user == nil {
+		return errors.BadRequest("users.ConfirmEmailChange", "invalid or expired token")
+	}
+	// Someone may have registered the address since the link was sent
+	if owner := indexOwner(emailKey(change.Email)); owner != "" && owner != user.Id {
+		return errors.Conflict("users.ConfirmEmailChange", "email already registered")
+	}
+
+	oldEmail := user.Email
+	user.Email = change.Email
+	// Following the link proves the new address
+	user.Verified = true
+	b, err := json.Marshal(user)
+	if err != nil {
+		return errors.InternalServerError("users.ConfirmEmailChange", "failed to encode user")
+	}
+	if err := userStore.Write(&store.Record{Key: "user-" + user.Id, Value: b}); err != nil {
+		return errors.InternalServerError("users.ConfirmEmailChange", "failed to save user")
+	}
+	if oldEmail != "" {
+		_ = userStore.Delete(emailKey(oldEmail))
+	}
+	_ = userStore.Write(&store.Record{Key: emailKey(user.Email), Value: []byte(user.Id)})
+	// A verification link for the old address no longer applies
+	if prev := indexOwner(verifyUserKey(user.Id)); prev != "" {
+		_ = userStore.Delete(verifyKey(prev))
+		_ = userStore.Delete(verifyUserKey(user.Id))
+	}
+
+	// Let the old address know, in case the change wasn't theirs
+	if oldEmail != "" {
+		msg := &mailer.Message{
+			To:      oldEmail,
+			Subject: "Your email address was changed",
+			Body: fmt.Sprintf("Hi %s,\n\nThe email address of your account was changed to %s. "+
+				"If you didn't do this, contact an administrator.\n",
+				user.Name, user.Email),
+		}
+		if err := h.opts.Mailer.Send(msg); err != nil {
+			log.Printf("Failed to send email change notice to %s: %v", oldEmail, err)
+		}
+	}
+
+	h.record(ctx, "user.email_change", "", user.Id, map[string]string{"old_email": oldEmail, "email": user.Email})
+	rsp.User = user
+	return nil
+}
@@ -0,0 +1,19 @@
+package handler
+
+import (
+	"context"
+
+	auditpb "github.com/micro/blog/audit/proto"
+)
+
+// record adds an event about a user account to the audit log. An empty
+// actor is filled in from the caller's metadata.
+func (h *Handler) record(ctx context.Context, action, actorID, targetID string, details map[string]string) {
+	h.opts.Audit.Record(ctx, &auditpb.Event{
+		Action:     action,
+		ActorId:    actorID,
+		TargetType: "user",
+		TargetId:   targetID,
+		Details:    details,
+	})
+}
@@ -0,0 +1,110 @@
+package handler
+
+import (
+	"context"
+	"time"
+
+	pb "github.com/micro/blog/users/proto"
+	"go-micro.dev/v5/errors"
+)
+
+// blockKey is the store key of a block; a user's blocks share the prefix
+// blockKey(id, "")
+func blockKey(blockerID, blockedID string) string {
+	return "block-" + blockerID + "|" + blockedID
+}
+
+func muteKey(muterID, mutedID string) string {
+	return "mute-" + muterID + "|" + mutedID
+}
+
+func hasRelation(key string) bool {
+	rec, err := userStore.Read(key)
+	return err == nil && len(rec) > 0
+}
+
+// addRelation stores a relation between two existing, different users
+func addRelation(method, key, userID, targetID string) error {
+	if userID == "" || targetID == "" {
+		return errors.BadRequest(method, "user and target required")
+	}
+	if userID == targetID {
+		return errors.BadRequest(method, "you can't do that to yourself")
+	}
+	if readUser(userID) == nil || readUser(targetID) == nil {
+		return errors.NotFound(method, "user not found")
+	}
+	if hasRelation(key) {
+		return nil
+	}
+	rel := &relation{UserID: userID, Target: targetID, CreatedAt: time.Now().Unix()}
+	if err := writeRelation(key, rel); err != nil {
+		return errors.InternalServerError(method, "failed to save")
+	}
+	return nil
+}
+
+// unblockAll removes a user's blocks and mutes and those of them. The
+// caller must hold mu.
+func unblockAll(userID string) {
+	for _, prefix := range []string{"block-", "mute-"} {
+		for _, rel := range readRelations(prefix) {
+			if rel.UserID == userID || rel.Target == userID {
+				_ = userStore.Delete(prefix + rel.UserID + "|" + rel.Target)
+			}
+		}
+	}
+}
+
+func (h *Handler) Block(ctx context.Context, req *pb.BlockRequest, rsp *pb.BlockResponse) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if err := addRelation("users.Block", blockKey(req.UserId, req.TargetId), req.UserId, req.TargetId); err != nil {
+		return err
+	}
+	// A blocked user no longer follows the blocker
+	_ = userStore.Delete(followKey(req.TargetId, req.UserId))
+	return nil
+}
+
+func (h *Handler) Unblock(ctx context.Context, req *pb.UnblockRequest, rsp *pb.UnblockResponse) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	_ = userStore.Delete(blockKey(req.UserId, req.TargetId))
+	return nil
+}
+
+func (h *Handler) Mute(ctx context.Context, req *pb.MuteRequest, rsp *pb.MuteResponse) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	return addRelation("users.Mute", muteKey(req.UserId, req.TargetId), req.UserId, req.TargetId)
+}
+
+func (h *Handler) Unmute(ctx context.Context, req *pb.UnmuteRequest, rsp *pb.UnmuteResponse) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	_ = userStore.Delete(muteKey(req.UserId, req.TargetId))
+	return nil
+}
+
+func (h *Handler) ListBlocks(ctx context.Context, req *pb.ListBlocksRequest, rsp *pb.ListBlocksResponse) error {
+	if req.UserId == "" {
+		return errors.BadRequest("users.ListBlocks", "user required")
+	}
+	for _, rel := range readRelations(blockKey(req.UserId, "")) {
+		rsp.BlockedIds = append(rsp.BlockedIds, rel.Target)
+	}
+	for _, rel := range readRelations(muteKey(req.UserId, "")) {
+		rsp.MutedIds = append(rsp.MutedIds, rel.Target)
+	}
+	return nil
+}
+
+func (h *Handler) IsBlocked(ctx context.Context, req *pb.IsBlockedRequest, rsp *pb.IsBlockedResponse) error {
+	rsp.Blocked = req.BlockerId != "" && req.UserId != "" && hasRelation(blockKey(req.BlockerId, req.UserId))
+	return nil
+}
@@ -0,0 +1,172 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	pb "github.com/micro/blog/users/proto"
+	"go-micro.dev/v5/errors"
+	"go-micro.dev/v5/store"
+)
+
+const (
+	deletionPending  = "pending"
+	deletionComplete = "complete"
+)
+
+// deletionModes are what can happen to a deleted user's posts and comments
+var deletionModes = map[string]bool{
+	"delete":    true,
+	"anonymize": true,
+}
+
+// deletionServices hold user content and report DeletionProgress under
+// these names
+var deletionServices = []string{"posts", "comments"}
+
+func deletionKey(id string) string {
+	return "deletion-" + id
+}
+
+func readDeletion(id string) *pb.Deletion {
+	rec, err := userStore.Read(deletionKey(id))
+	if err != nil || len(rec) == 0 {
+		return nil
+	}
+	var d pb.Deletion
+	if err := json.Unmarshal(rec[0].Value, &d); err != nil {
+		return nil
+	}
+	return &d
+}
+
+func writeDeletion(d *pb.Deletion) error {
+	b, err := json.Marshal(d)
+	if err != nil {
+		return err
+	}
+	return userStore.Write(&store.Record{Key: deletionKey(d.Id), Value: b})
+}
+
+// publishDeletion asks the content services to process a deletion. Failures
+// are retried by retryDeletions.
+func (h *Handler) publishDeletion(ctx context.Context, d *pb.Deletion) {
+	if h.opts.UserDeleted == nil {
+		log.Printf("No UserDeleted publisher, deletion %s stays pending", d.Id)
+		return
+	}
+	err := h.opts.UserDeleted.Publish(ctx, &pb.UserDeleted{
+		DeletionId: d.Id,
+		UserId:     d.UserId,
+		Mode:       d.Mode,
+	})
+	if err != nil {
+		log.Printf("Failed to publish deletion %s, will retry: %v", d.Id, err)
+	}
+}
+
+func (h *Handler) DeleteAccount(ctx context.Context, req *pb.DeleteAccountRequest, rsp *pb.DeleteAccountResponse) error {
+	if !deletionModes[req.Mode] {
+		return errors.BadRequest("users.DeleteAccount", `mode must be "delete" or "anonymize"`)
+	}
+
+	mu.Lock()
+	user := readUser(req.UserId)
+	if user == nil {
+		mu.Unlock()
+		return errors.NotFound("users.DeleteAccount", "user not found")
+	}
+	// Leave someone able to administer the blog
+	if slices.Contains(user.Roles, adminRole) && countAdmins() == 1 {
+		if rec, err := userStore.Read("user-", store.ReadPrefix()); err == nil && len(rec) > 1 {
+			mu.Unlock()
+			return errors.Conflict("users.DeleteAccount", "make another user an admin before deleting the last admin account")
+		}
+	}
+
+	now := time.Now().Unix()
+	d := &pb.Deletion{
+		Id:        uuid.New().String(),
+		UserId:    user.Id,
+		Mode:      req.Mode,
+		Status:    deletionPending,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	for _, svc := range deletionServices {
+		d.Steps = append(d.Steps, &pb.DeletionStep{Service: svc})
+	}
+	// Record the deletion before removing the user so that it is resumed
+	// if we stop in between
+	if err := writeDeletion(d); err != nil {
+		mu.Unlock()
+		return errors.InternalServerError("users.DeleteAccount", "failed to save deletion")
+	}
+	deleteUser(user.Id)
+	mu.Unlock()
+
+	h.record(ctx, "user.delete", user.Id, user.Id, map[string]string{"mode": req.Mode, "deletion_id": d.Id})
+	h.publishDeletion(ctx, d)
+	rsp.Deletion = d
+	return nil
+}
+
+func (h *Handler) ReadDeletion(ctx context.Context, req *pb.ReadDeletionRequest, rsp *pb.ReadDeletionResponse) error {
+	d := readDeletion(req.Id)
+	if d == nil {
+		return errors.NotFound("users.ReadDeletion", "deletion not found")
+	}
+	rsp.Deletion = d
+	return nil
+}
+
+// DeletionProgress handles progress events from the content services. It
+// is subscribed to the "user.deletion.progress" topic.
+func (h *Handler) DeletionProgress(ctx context.Context, ev *pb.DeletionProgress) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	d := readDeletion(ev.DeletionId)
+	if d == nil || d.Status == deletionComplete {
+		return nil
+	}
+	complete := true
+	for _, step := range d.Steps {
+		if step.Service == ev.Service && !step.Done {
+			step.Items += ev.Items
+			step.Done = ev.Done
+		}
+		complete = complete && step.Done
+	}
+	d.UpdatedAt = time.Now().Unix()
+	if complete {
+		d.Status = deletionComplete
+		d.CompletedAt = d.UpdatedAt
+	}
+	return writeDeletion(d)
+}
+
+// retryDeletions publishes pending deletions again when they have made no
+// progress for the retry interval, until every service reports them done
+func (h *Handler) retryDeletions() {
+	for range time.Tick(h.opts.DeletionRetry) {
+		rec, err := userStore.Read("deletion-", store.ReadPrefix())
+		if err != nil {
+			continue
+		}
+		stale := time.Now().Add(-h.opts.DeletionRetry).Unix()
+		for _, r := range rec {
+			var d pb.Deletion
+			if err := json.Unmarshal(r.Value, &d); err != nil {
+				continue
+			}
+			if d.Status == deletionPending && d.UpdatedAt <= stale {
+				h.publishDeletion(context.Background(), &d)
+			}
+		}
+	}
+}
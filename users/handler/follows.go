@@ -0,0 +1,175 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+
+	pb "github.com/micro/blog/users/proto"
+	"go-micro.dev/v5/errors"
+	"go-micro.dev/v5/store"
+)
+
+// maxTagLength bounds followed tags
+const maxTagLength = 64
+
+// relation is a user's follow, block or mute of another user, or follow
+// of a tag, stored under a key such as followKey
+type relation struct {
+	UserID    string `json:"user_id"`
+	Target    string `json:"target"` // the other user's ID or the tag
+	CreatedAt int64  `json:"created_at"`
+}
+
+// followKey is the store key of a follow. User IDs never contain "|", so
+// a user's follows share the prefix followKey(id, "").
+func followKey(followerID, followeeID string) string {
+	return "follow-" + followerID + "|" + followeeID
+}
+
+func followTagKey(userID, tag string) string {
+	return "followtag-" + userID + "|" + tag
+}
+
+func normalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimSpace(tag))
+}
+
+// readRelations returns the relations stored under a key prefix, oldest
+// first
+func readRelations(prefix string) []*relation {
+	rec, err := userStore.Read(prefix, store.ReadPrefix())
+	if err != nil {
+		return nil
+	}
+	var rels []*relation
+	for _, r := range rec {
+		var rel relation
+		if err := json.Unmarshal(r.Value, &rel); err == nil {
+			rels = append(rels, &rel)
+		}
+	}
+	sort.SliceStable(rels, func(i, j int) bool { return rels[i].CreatedAt < rels[j].CreatedAt })
+	return rels
+}
+
+func writeRelation(key string, rel *relation) error {
+	b, err := json.Marshal(rel)
+	if err != nil {
+		return err
+	}
+	return userStore.Write(&store.Record{Key: key, Value: b})
+}
+
+// unfollowAll removes a user's follows and everyone's follows of them.
+// The caller must hold mu.
+func unfollowAll(userID string) {
+	for _, f := range readRelations(followKey(userID, "")) {
+		_ = userStore.Delete(followKey(userID, f.Target))
+	}
+	for _, f := range readRelations("follow-") {
+		if f.Target == userID {
+			_ = userStore.Delete(followKey(f.UserID, userID))
+		}
+	}
+	for _, f := range readRelations(followTagKey(userID, "")) {
+		_ = userStore.Delete(followTagKey(userID, f.Target))
+	}
+}
+
+func (h *Handler) Follow(ctx context.Context, req *pb.FollowRequest, rsp *pb.FollowResponse) error {
+	if req.FollowerId == "" || req.FolloweeId == "" {
+		return errors.BadRequest("users.Follow", "follower and followee required")
+	}
+	if req.FollowerId == req.FolloweeId {
+		return errors.BadRequest("users.Follow", "you can't follow yourself")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if readUser(req.FollowerId) == nil || readUser(req.FolloweeId) == nil {
+		return errors.NotFound("users.Follow", "user not found")
+	}
+	if hasRelation(blockKey(req.FolloweeId, req.FollowerId)) {
+		return errors.Forbidden("users.Follow", "you can't follow this user")
+	}
+	key := followKey(req.FollowerId, req.FolloweeId)
+	if rec, err := userStore.Read(key); err == nil && len(rec) > 0 {
+		return nil
+	}
+	f := &relation{UserID: req.FollowerId, Target: req.FolloweeId, CreatedAt: time.Now().Unix()}
+	if err := writeRelation(key, f); err != nil {
+		return errors.InternalServerError("users.Follow", "failed to save follow")
+	}
+	return nil
+}
+
+func (h *Handler) Unfollow(ctx context.Context, req *pb.UnfollowRequest, rsp *pb.UnfollowResponse) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	_ = userStore.Delete(followKey(req.FollowerId, req.FolloweeId))
+	return nil
+}
+
+func (h *Handler) FollowTag(ctx context.Context, req *pb.FollowTagRequest, rsp *pb.FollowTagResponse) error {
+	tag := normalizeTag(req.Tag)
+	if req.UserId == "" || tag == "" {
+		return errors.BadRequest("users.FollowTag", "user and tag required")
+	}
+	if len(tag) > maxTagLength {
+		return errors.BadRequest("users.FollowTag", "tag must be at most %d characters", maxTagLength)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if readUser(req.UserId) == nil {
+		return errors.NotFound("users.FollowTag", "user not found")
+	}
+	key := followTagKey(req.UserId, tag)
+	if rec, err := userStore.Read(key); err == nil && len(rec) > 0 {
+		return nil
+	}
+	f := &relation{UserID: req.UserId, Target: tag, CreatedAt: time.Now().Unix()}
+	if err := writeRelation(key, f); err != nil {
+		return errors.InternalServerError("users.FollowTag", "failed to save follow")
+	}
+	return nil
+}
+
+func (h *Handler) UnfollowTag(ctx context.Context, req *pb.UnfollowTagRequest, rsp *pb.UnfollowTagResponse) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	_ = userStore.Delete(followTagKey(req.UserId, normalizeTag(req.Tag)))
+	return nil
+}
+
+func (h *Handler) ListFollowing(ctx context.Context, req *pb.ListFollowingRequest, rsp *pb.ListFollowingResponse) error {
+	if req.UserId == "" {
+		return errors.BadRequest("users.ListFollowing", "user required")
+	}
+	for _, f := range readRelations(followKey(req.UserId, "")) {
+		rsp.UserIds = append(rsp.UserIds, f.Target)
+	}
+	for _, f := range readRelations(followTagKey(req.UserId, "")) {
+		rsp.Tags = append(rsp.Tags, f.Target)
+	}
+	return nil
+}
+
+func (h *Handler) ListFollowers(ctx context.Context, req *pb.ListFollowersRequest, rsp *pb.ListFollowersResponse) error {
+	if req.UserId == "" {
+		return errors.BadRequest("users.ListFollowers", "user required")
+	}
+	for _, f := range readRelations("follow-") {
+		if f.Target == req.UserId {
+			rsp.UserIds = append(rsp.UserIds, f.UserID)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,181 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	pb "github.com/micro/blog/users/proto"
+	"go-micro.dev/v5/errors"
+)
+
+func following(t *testing.T, h *Handler, userID string) *pb.ListFollowingResponse {
+	t.Helper()
+	var rsp pb.ListFollowingResponse
+	if err := h.ListFollowing(context.Background(), &pb.ListFollowingRequest{UserId: userID}, &rsp); err != nil {
+		t.Fatalf("ListFollowing: %v", err)
+	}
+	return &rsp
+}
+
+func TestFollow(t *testing.T) {
+	h, _ := newTestHandler(t)
+	jane := createUser(t, h, "Jane Doe", "jane@example.com", "secret-password")
+	john := createUser(t, h, "John Doe", "john@example.com", "secret-password")
+
+	// Following twice is one follow
+	for i := 0; i < 2; i++ {
+		if err := h.Follow(context.Background(), &pb.FollowRequest{FollowerId: john.Id, FolloweeId: jane.Id}, &pb.FollowResponse{}); err != nil {
+			t.Fatalf("Follow: %v", err)
+		}
+	}
+	if ids := following(t, h, john.Id).UserIds; len(ids) != 1 || ids[0] != jane.Id {
+		t.Errorf("john follows %v, want jane", ids)
+	}
+	var followers pb.ListFollowersResponse
+	if err := h.ListFollowers(context.Background(), &pb.ListFollowersRequest{UserId: jane.Id}, &followers); err != nil || len(followers.UserIds) != 1 || followers.UserIds[0] != john.Id {
+		t.Errorf("jane's followers = %v (%v), want john", followers.UserIds, err)
+	}
+
+	tests := []struct {
+		name               string
+		follower, followee string
+		want               int32
+	}{
+		{"self", jane.Id, jane.Id, http.StatusBadRequest},
+		{"missing follower", "", jane.Id, http.StatusBadRequest},
+		{"unknown user", john.Id, "nobody", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := h.Follow(context.Background(), &pb.FollowRequest{FollowerId: tt.follower, FolloweeId: tt.followee}, &pb.FollowResponse{})
+			if errors.FromError(err).Code != tt.want {
+				t.Errorf("Follow = %v, want %d", err, tt.want)
+			}
+		})
+	}
+	if ids := following(t, h, jane.Id).UserIds; len(ids) != 0 {
+		t.Errorf("jane follows %v after rejected follows", ids)
+	}
+
+	// Unfollowing twice is harmless
+	for i := 0; i < 2; i++ {
+		if err := h.Unfollow(context.Background(), &pb.UnfollowRequest{FollowerId: john.Id, FolloweeId: jane.Id}, &pb.UnfollowResponse{}); err != nil {
+			t.Fatalf("Unfollow: %v", err)
+		}
+	}
+	if ids := following(t, h, john.Id).UserIds; len(ids) != 0 {
+		t.Errorf("john follows %v after unfollowing", ids)
+	}
+}
+
+func TestFollowTag(t *testing.T) {
+	h, _ := newTestHandler(t)
+	jane := createUser(t, h, "Jane Doe", "jane@example.com", "secret-password")
+
+	// Tags are followed case insensitively, once
+	for _, tag := range []string{"Go", " go "} {
+		if err := h.FollowTag(context.Background(), &pb.FollowTagRequest{UserId: jane.Id, Tag: tag}, &pb.FollowTagResponse{}); err != nil {
+			t.Fatalf("FollowTag(%q): %v", tag, err)
+		}
+	}
+	if tags := following(t, h, jane.Id).Tags; len(tags) != 1 || tags[0] != "go" {
+		t.Errorf("jane follows tags %v, want go", tags)
+	}
+
+	for _, tag := range []string{"", strings.Repeat("a", maxTagLength+1)} {
+		err := h.FollowTag(context.Background(), &pb.FollowTagRequest{UserId: jane.Id, Tag: tag}, &pb.FollowTagResponse{})
+		if errors.FromError(err).Code != http.StatusBadRequest {
+			t.Errorf("FollowTag(%q) = %v, want 400", tag, err)
+		}
+	}
+
+	if err := h.UnfollowTag(context.Background(), &pb.UnfollowTagRequest{UserId: jane.Id, Tag: "GO"}, &pb.UnfollowTagResponse{}); err != nil {
+		t.Fatalf("UnfollowTag: %v", err)
+	}
+	if tags := following(t, h, jane.Id).Tags; len(tags) != 0 {
+		t.Errorf("jane follows tags %v after unfollowing", tags)
+	}
+}
+
+func TestBlock(t *testing.T) {
+	h, _ := newTestHandler(t)
+	jane := createUser(t, h, "Jane Doe", "jane@example.com", "secret-password")
+	john := createUser(t, h, "John Doe", "john@example.com", "secret-password")
+	if err := h.Follow(context.Background(), &pb.FollowRequest{FollowerId: john.Id, FolloweeId: jane.Id}, &pb.FollowResponse{}); err != nil {
+		t.Fatalf("Follow: %v", err)
+	}
+
+	// Blocking twice is one block
+	for i := 0; i < 2; i++ {
+		if err := h.Block(context.Background(), &pb.BlockRequest{UserId: jane.Id, TargetId: john.Id}, &pb.BlockResponse{}); err != nil {
+			t.Fatalf("Block: %v", err)
+		}
+	}
+	var blocks pb.ListBlocksResponse
+	if err := h.ListBlocks(context.Background(), &pb.ListBlocksRequest{UserId: jane.Id}, &blocks); err != nil || len(blocks.BlockedIds) != 1 {
+		t.Errorf("jane blocks %v (%v), want john", blocks.BlockedIds, err)
+	}
+	var blocked pb.IsBlockedResponse
+	if err := h.IsBlocked(context.Background(), &pb.IsBlockedRequest{BlockerId: jane.Id, UserId: john.Id}, &blocked); err != nil || !blocked.Blocked {
+		t.Errorf("IsBlocked = %v (%v), want true", blocked.Blocked, err)
+	}
+	if err := h.IsBlocked(context.Background(), &pb.IsBlockedRequest{BlockerId: john.Id, UserId: jane.Id}, &blocked); err != nil || blocked.Blocked {
+		t.Errorf("IsBlocked the other way = %v (%v), want false", blocked.Blocked, err)
+	}
+
+	// The block ends john's follow and stops them following again
+	if ids := following(t, h, john.Id).UserIds; len(ids) != 0 {
+		t.Errorf("blocked john still follows %v", ids)
+	}
+	err := h.Follow(context.Background(), &pb.FollowRequest{FollowerId: john.Id, FolloweeId: jane.Id}, &pb.FollowResponse{})
+	if errors.FromError(err).Code != http.StatusForbidden {
+		t.Errorf("Follow of a blocker = %v, want 403", err)
+	}
+
+	// Nobody blocks or mutes themselves
+	err = h.Block(context.Background(), &pb.BlockRequest{UserId: jane.Id, TargetId: jane.Id}, &pb.BlockResponse{})
+	if errors.FromError(err).Code != http.StatusBadRequest {
+		t.Errorf("Block of yourself = %v, want 400", err)
+	}
+	err = h.Mute(context.Background(), &pb.MuteRequest{UserId: jane.Id, TargetId: jane.Id}, &pb.MuteResponse{})
+	if errors.FromError(err).Code != http.StatusBadRequest {
+		t.Errorf("Mute of yourself = %v, want 400", err)
+	}
+
+	if err := h.Unblock(context.Background(), &pb.UnblockRequest{UserId: jane.Id, TargetId: john.Id}, &pb.UnblockResponse{}); err != nil {
+		t.Fatalf("Unblock: %v", err)
+	}
+	if err := h.Follow(context.Background(), &pb.FollowRequest{FollowerId: john.Id, FolloweeId: jane.Id}, &pb.FollowResponse{}); err != nil {
+		t.Errorf("Follow after an unblock: %v", err)
+	}
+}
+
+func TestMute(t *testing.T) {
+	h, _ := newTestHandler(t)
+	jane := createUser(t, h, "Jane Doe", "jane@example.com", "secret-password")
+	john := createUser(t, h, "John Doe", "john@example.com", "secret-password")
+
+	for i := 0; i < 2; i++ {
+		if err := h.Mute(context.Background(), &pb.MuteRequest{UserId: jane.Id, TargetId: john.Id}, &pb.MuteResponse{}); err != nil {
+			t.Fatalf("Mute: %v", err)
+		}
+	}
+	var blocks pb.ListBlocksResponse
+	if err := h.ListBlocks(context.Background(), &pb.ListBlocksRequest{UserId: jane.Id}, &blocks); err != nil || len(blocks.MutedIds) != 1 || len(blocks.BlockedIds) != 0 {
+		t.Errorf("jane blocks %v and mutes %v (%v), want john muted", blocks.BlockedIds, blocks.MutedIds, err)
+	}
+	// A mute is private: john isn't blocked and can still follow
+	if err := h.Follow(context.Background(), &pb.FollowRequest{FollowerId: john.Id, FolloweeId: jane.Id}, &pb.FollowResponse{}); err != nil {
+		t.Errorf("Follow of a user who muted you: %v", err)
+	}
+
+	if err := h.Unmute(context.Background(), &pb.UnmuteRequest{UserId: jane.Id, TargetId: john.Id}, &pb.UnmuteResponse{}); err != nil {
+		t.Fatalf("Unmute: %v", err)
+	}
+	blocks = pb.ListBlocksResponse{}
+	if err := h.ListBlocks(context.Background(), &pb.ListBlocksRequest{UserId: jane.Id}, &blocks); err != nil || len(blocks.MutedIds) != 0 {
+		t.Errorf("jane mutes %v (%v) after unmuting", blocks.MutedIds, err)
+	}
+}
@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/micro/blog/users/mailer"
+	pb "github.com/micro/blog/users/proto"
+	"go-micro.dev/v5/store"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// testPasswordPolicy keeps hashing cheap so tests run fast
+var testPasswordPolicy = PasswordPolicy{
+	Algorithm:     Bcrypt,
+	BcryptCost:    bcrypt.MinCost,
+	Argon2Time:    1,
+	Argon2Memory:  8,
+	Argon2Threads: 1,
+}
+
+// newTestHandler returns a handler with an empty store that mails to an
+// outbox in a temporary directory
+func newTestHandler(t *testing.T, opts ...Option) (*Handler, *mailer.Outbox) {
+	t.Helper()
+	// The file store the service runs with; the memory store's prefix reads
+	// return nothing without a limit
+	userStore = store.NewFileStore(store.DirOption(t.TempDir()))
+	t.Cleanup(func() { userStore.Close() })
+	outbox, err := mailer.NewOutbox(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts = append([]Option{WithMailer(outbox), WithPasswordPolicy(testPasswordPolicy)}, opts...)
+	return New(opts...), outbox
+}
+
+// createUser signs a user up with a password
+func createUser(t *testing.T, h *Handler, name, email, password string) *pb.User {
+	t.Helper()
+	var rsp pb.CreateResponse
+	if err := h.Create(context.Background(), &pb.CreateRequest{Name: name, Email: email, Password: password}, &rsp); err != nil {
+		t.Fatalf("Create(%s): %v", email, err)
+	}
+	return rsp.User
+}
+
+var mailToken = regexp.MustCompile(`token=([A-Za-z0-9_-]+)`)
+
+// lastToken returns the token in the link of the latest mail to an address
+func lastToken(t *testing.T, outbox *mailer.Outbox, to string) string {
+	t.Helper()
+	msg, err := outbox.Last(to)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg == nil {
+		t.Fatalf("no mail to %s", to)
+	}
+	m := mailToken.FindStringSubmatch(msg.Body)
+	if m == nil {
+		t.Fatalf("no token in mail to %s: %q", to, msg.Body)
+	}
+	return m[1]
+}
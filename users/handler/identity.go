@@ -0,0 +1,198 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/micro/blog/users/mailer"
+	pb "github.com/micro/blog/users/proto"
+	"go-micro.dev/v5/errors"
+	"go-micro.dev/v5/store"
+)
+
+// identityKey is the store key of an external identity. Issuers are URLs
+// and never contain "|".
+func identityKey(issuer, subject string) string {
+	return "identity-" + issuer + "|" + subject
+}
+
+func readIdentity(issuer, subject string) *pb.Identity {
+	rec, err := userStore.Read(identityKey(issuer, subject))
+	if err != nil || len(rec) == 0 {
+		return nil
+	}
+	var id pb.Identity
+	if err := json.Unmarshal(rec[0].Value, &id); err != nil {
+		return nil
+	}
+	return &id
+}
+
+// userIdentities returns the identities linked to a user
+func userIdentities(userID string) []*pb.Identity {
+	rec, err := userStore.Read("identity-", store.ReadPrefix())
+	if err != nil {
+		return nil
+	}
+	var ids []*pb.Identity
+	for _, r := range rec {
+		var id pb.Identity
+		if err := json.Unmarshal(r.Value, &id); err == nil && id.UserId == userID {
+			ids = append(ids, &id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i].LinkedAt < ids[j].LinkedAt })
+	return ids
+}
+
+// unlinkIdentities removes every identity linked to a user
+func unlinkIdentities(userID string) {
+	for _, id := range userIdentities(userID) {
+		_ = userStore.Delete(identityKey(id.Issuer, id.Subject))
+	}
+}
+
+func linkIdentity(req *pb.ResolveIdentityRequest, userID string) error {
+	b, err := json.Marshal(&pb.Identity{
+		Issuer:   req.Issuer,
+		Subject:  req.Subject,
+		UserId:   userID,
+		Email:    normalizeEmail(req.Email),
+		LinkedAt: time.Now().Unix(),
+	})
+	if err != nil {
+		return errors.InternalServerError("users.ResolveIdentity", "failed to encode identity")
+	}
+	if err := userStore.Write(&store.Record{Key: identityKey(req.Issuer, req.Subject), Value: b}); err != nil {
+		return errors.InternalServerError("users.ResolveIdentity", "failed to save identity")
+	}
+	return nil
+}
+
+// freeHandle returns the first unused handle based on the given names
+func freeHandle(names ...string) string {
+	base := ""
+	for _, n := range names {
+		if base = handleFromName(n); base != "" {
+			break
+		}
+	}
+	if base == "" {
+		base = "user"
+	}
+	if len(base) > 27 {
+		base = strings.Trim(base[:27], "-")
+	}
+	handle := base
+	for i := 2; indexOwner(handleKey(handle)) != ""; i++ {
+		handle = fmt.Sprintf("%s-%d", base, i)
+	}
+	return handle
+}
+
+func (h *Handler) ResolveIdentity(ctx context.Context, req *pb.ResolveIdentityRequest, rsp *pb.ResolveIdentityResponse) error {
+	if req.Issuer == "" || req.Subject == "" {
+		return errors.BadRequest("users.ResolveIdentity", "issuer and subject required")
+	}
+	verification, err := h.resolveIdentity(ctx, req, rsp)
+	if err != nil {
+		return err
+	}
+	h.sendVerification(verification)
+	return nil
+}
+
+// resolveIdentity does the work of ResolveIdentity under mu. It returns the
+// verification mail of a user it created, to send once mu is released.
+func (h *Handler) resolveIdentity(ctx context.Context, req *pb.ResolveIdentityRequest, rsp *pb.ResolveIdentityResponse) (*mailer.Message, error) {
+	email := normalizeEmail(req.Email)
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	// A known identity logs in as its user
+	if id := readIdentity(req.Issuer, req.Subject); id != nil {
+		if req.UserId != "" && id.UserId != req.UserId {
+			return nil, errors.Conflict("users.ResolveIdentity", "this account is linked to another user")
+		}
+		if user := readUser(id.UserId); user != nil {
+			rsp.User = user
+			return nil, nil
+		}
+		// The user was deleted; link afresh below
+		_ = userStore.Delete(identityKey(req.Issuer, req.Subject))
+	}
+
+	// Connect the identity to the logged in user
+	if req.UserId != "" {
+		user := readUser(req.UserId)
+		if user == nil {
+			return nil, errors.NotFound("users.ResolveIdentity", "user not found")
+		}
+		if err := linkIdentity(req, user.Id); err != nil {
+			return nil, err
+		}
+		h.record(ctx, "user.identity_link", user.Id, user.Id, map[string]string{"issuer": req.Issuer})
+		rsp.User = user
+		return nil, nil
+	}
+
+	if email == "" {
+		return nil, errors.BadRequest("users.ResolveIdentity", "the provider did not share an email address")
+	}
+	// Only providers configured to be trusted can vouch for an address;
+	// others might let users claim any email
+	verified := req.TrustEmail && req.EmailVerified
+	if owner := indexOwner(emailKey(email)); owner != "" {
+		// Linking logs the caller in without the account's password, so
+		// it needs a verified address from a trusted provider
+		if !verified {
+			return nil, errors.Conflict("users.ResolveIdentity", "email already registered; log in and connect this provider from your account")
+		}
+		user := readUser(owner)
+		if user == nil {
+			return nil, errors.InternalServerError("users.ResolveIdentity", "failed to read user")
+		}
+		if !user.Verified {
+			user.Verified = true
+			if b, err := json.Marshal(user); err == nil {
+				_ = userStore.Write(&store.Record{Key: "user-" + user.Id, Value: b})
+			}
+		}
+		if err := linkIdentity(req, user.Id); err != nil {
+			return nil, err
+		}
+		h.record(ctx, "user.identity_link", "", user.Id, map[string]string{"issuer": req.Issuer})
+		rsp.User = user
+		return nil, nil
+	}
+
+	name := req.Name
+	if name == "" {
+		name = req.PreferredUsername
+	}
+	if name == "" {
+		name = strings.Split(email, "@")[0]
+	}
+	handle := freeHandle(req.PreferredUsername, req.Name, strings.Split(email, "@")[0])
+	user, verification, err := h.createUser(name, email, handle, "", verified)
+	if err != nil {
+		return nil, err
+	}
+	if err := linkIdentity(req, user.Id); err != nil {
+		return nil, err
+	}
+	h.record(ctx, "user.create", user.Id, user.Id, map[string]string{"issuer": req.Issuer})
+	rsp.User = user
+	rsp.Created = true
+	return verification, nil
+}
+
+func (h *Handler) ListIdentities(ctx context.Context, req *pb.ListIdentitiesRequest, rsp *pb.ListIdentitiesResponse) error {
+	rsp.Identities = userIdentities(req.UserId)
+	return nil
+}
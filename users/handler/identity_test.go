@@ -0,0 +1,160 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	pb "github.com/micro/blog/users/proto"
+	"go-micro.dev/v5/errors"
+)
+
+const testIssuer = "https://id.example.com"
+
+func resolve(h *Handler, req *pb.ResolveIdentityRequest) (*pb.ResolveIdentityResponse, error) {
+	var rsp pb.ResolveIdentityResponse
+	err := h.ResolveIdentity(context.Background(), req, &rsp)
+	return &rsp, err
+}
+
+func TestResolveIdentityCreatesUser(t *testing.T) {
+	h, _ := newTestHandler(t)
+	req := &pb.ResolveIdentityRequest{
+		Issuer:            testIssuer,
+		Subject:           "1234",
+		Email:             "Jane@Example.com",
+		EmailVerified:     true,
+		TrustEmail:        true,
+		Name:              "Jane Doe",
+		PreferredUsername: "jdoe",
+	}
+	rsp, err := resolve(h, req)
+	if err != nil {
+		t.Fatalf("ResolveIdentity: %v", err)
+	}
+	if !rsp.Created || rsp.User.Email != "jane@example.com" || rsp.User.Handle != "jdoe" || !rsp.User.Verified {
+		t.Fatalf("unexpected new user %+v (created %v)", rsp.User, rsp.Created)
+	}
+
+	// The same identity logs in as the same user, even if its email changed
+	req.Email = "other@example.com"
+	again, err := resolve(h, req)
+	if err != nil {
+		t.Fatalf("ResolveIdentity again: %v", err)
+	}
+	if again.Created || again.User.Id != rsp.User.Id {
+		t.Errorf("second login resolved to %s (created %v), want %s", again.User.Id, again.Created, rsp.User.Id)
+	}
+}
+
+func TestResolveIdentityUntrustedEmailNotVerified(t *testing.T) {
+	h, outbox := newTestHandler(t)
+	rsp, err := resolve(h, &pb.ResolveIdentityRequest{
+		Issuer:        testIssuer,
+		Subject:       "1234",
+		Email:         "jane@example.com",
+		EmailVerified: true,
+	})
+	if err != nil {
+		t.Fatalf("ResolveIdentity: %v", err)
+	}
+	// The user confirms the address like any other signup
+	if !rsp.Created || rsp.User.Verified {
+		t.Fatalf("new user from an untrusted provider: %+v (created %v), want unverified", rsp.User, rsp.Created)
+	}
+	lastToken(t, outbox, "jane@example.com")
+}
+
+func TestResolveIdentityLinksVerifiedEmail(t *testing.T) {
+	h, _ := newTestHandler(t)
+	user := createUser(t, h, "Jane Doe", "jane@example.com", "secret-password")
+
+	rsp, err := resolve(h, &pb.ResolveIdentityRequest{
+		Issuer:        testIssuer,
+		Subject:       "1234",
+		Email:         "jane@example.com",
+		EmailVerified: true,
+		TrustEmail:    true,
+	})
+	if err != nil {
+		t.Fatalf("ResolveIdentity: %v", err)
+	}
+	if rsp.Created || rsp.User.Id != user.Id {
+		t.Fatalf("resolved to %s (created %v), want the existing user %s", rsp.User.Id, rsp.Created, user.Id)
+	}
+	// The provider vouched for the address
+	if u := readUser(user.Id); u == nil || !u.Verified {
+		t.Error("linking a verified email didn't verify the user")
+	}
+	if ids := userIdentities(user.Id); len(ids) != 1 || ids[0].Subject != "1234" {
+		t.Errorf("identities = %v, want the linked one", ids)
+	}
+}
+
+func TestResolveIdentityEmailConflict(t *testing.T) {
+	tests := []struct {
+		name              string
+		verified, trusted bool
+	}{
+		// Anyone can claim an address at a provider that doesn't check it
+		{"unverified", false, true},
+		// or at one that says it does but isn't trusted to
+		{"untrusted", true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, _ := newTestHandler(t)
+			user := createUser(t, h, "Jane Doe", "jane@example.com", "secret-password")
+
+			// That mustn't take over the account
+			_, err := resolve(h, &pb.ResolveIdentityRequest{
+				Issuer:        testIssuer,
+				Subject:       "1234",
+				Email:         "jane@example.com",
+				EmailVerified: tt.verified,
+				TrustEmail:    tt.trusted,
+			})
+			if errors.FromError(err).Code != http.StatusConflict {
+				t.Fatalf("ResolveIdentity = %v, want 409", err)
+			}
+			if readIdentity(testIssuer, "1234") != nil {
+				t.Error("identity was linked despite the conflict")
+			}
+			if u := readUser(user.Id); u == nil || u.Verified {
+				t.Error("the provider's email verified the user")
+			}
+
+			// Logged in, the user can connect it themselves
+			rsp, err := resolve(h, &pb.ResolveIdentityRequest{
+				Issuer:  testIssuer,
+				Subject: "1234",
+				Email:   "jane@example.com",
+				UserId:  user.Id,
+			})
+			if err != nil {
+				t.Fatalf("ResolveIdentity for the logged in user: %v", err)
+			}
+			if rsp.User.Id != user.Id {
+				t.Errorf("connected to %s, want %s", rsp.User.Id, user.Id)
+			}
+		})
+	}
+}
+
+func TestResolveIdentityLinkedToAnotherUser(t *testing.T) {
+	h, _ := newTestHandler(t)
+	jane := createUser(t, h, "Jane Doe", "jane@example.com", "secret-password")
+	john := createUser(t, h, "John Doe", "john@example.com", "secret-password")
+
+	req := &pb.ResolveIdentityRequest{Issuer: testIssuer, Subject: "1234", Email: "jane@example.com", UserId: jane.Id}
+	if _, err := resolve(h, req); err != nil {
+		t.Fatalf("ResolveIdentity: %v", err)
+	}
+	req.UserId = john.Id
+	if _, err := resolve(h, req); errors.FromError(err).Code != http.StatusConflict {
+		t.Errorf("connecting an identity linked to another user = %v, want 409", err)
+	}
+	if id := readIdentity(testIssuer, "1234"); id == nil || id.UserId != jane.Id {
+		t.Errorf("identity now belongs to %v, want %s", id, jane.Id)
+	}
+}
@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"time"
+
+	"github.com/micro/blog/audit/recorder"
+	"github.com/micro/blog/users/mailer"
+	"go-micro.dev/v5"
+)
+
+type Options struct {
+	// Mailer delivers account email such as password reset links
+	Mailer mailer.Mailer
+	// BaseURL is the public address of the web frontend used in links
+	BaseURL string
+	// ResetTokenTTL is how long a password reset link stays valid
+	ResetTokenTTL time.Duration
+	// VerifyTokenTTL is how long an email verification link stays valid
+	VerifyTokenTTL time.Duration
+	// AdminEmail is granted the admin role on startup, to recover access
+	// to stores that have no admin
+	AdminEmail string
+	// TOTPIssuer names the blog in authenticator apps
+	TOTPIssuer string
+	// UserDeleted publishes UserDeleted events to the services holding
+	// a deleted user's content
+	UserDeleted micro.Event
+	// DeletionRetry is how long a deletion waits for progress before its
+	// UserDeleted event is published again
+	DeletionRetry time.Duration
+	// PasswordPolicy is how new password hashes are made. Hashes made
+	// under another policy are upgraded at login.
+	PasswordPolicy PasswordPolicy
+	// Audit records account changes in the audit log
+	Audit *recorder.Recorder
+}
+
+type Option func(o *Options)
+
+func WithMailer(m mailer.Mailer) Option {
+	return func(o *Options) {
+		o.Mailer = m
+	}
+}
+
+func WithBaseURL(url string) Option {
+	return func(o *Options) {
+		o.BaseURL = url
+	}
+}
+
+func WithResetTokenTTL(d time.Duration) Option {
+	return func(o *Options) {
+		o.ResetTokenTTL = d
+	}
+}
+
+func WithVerifyTokenTTL(d time.Duration) Option {
+	return func(o *Options) {
+		o.VerifyTokenTTL = d
+	}
+}
+
+func WithAdminEmail(email string) Option {
+	return func(o *Options) {
+		o.AdminEmail = email
+	}
+}
+
+func WithTOTPIssuer(issuer string) Option {
+	return func(o *Options) {
+		o.TOTPIssuer = issuer
+	}
+}
+
+func WithUserDeleted(e micro.Event) Option {
+	return func(o *Options) {
+		o.UserDeleted = e
+	}
+}
+
+func WithDeletionRetry(d time.Duration) Option {
+	return func(o *Options) {
+		o.DeletionRetry = d
+	}
+}
+
+func WithPasswordPolicy(p PasswordPolicy) Option {
+	return func(o *Options) {
+		o.PasswordPolicy = p
+	}
+}
+
+func WithAudit(r *recorder.Recorder) Option {
+	return func(o *Options) {
+		o.Audit = r
+	}
+}
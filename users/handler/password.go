@@ -0,0 +1,174 @@
+package handler
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"log"
+	"strings"
+
+	"go-micro.dev/v5/store"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Password hashing algorithms
+const (
+	Bcrypt   = "bcrypt"
+	Argon2id = "argon2id"
+)
+
+// PasswordPolicy is how new password hashes are made. Stored hashes carry
+// their own algorithm and parameters, so changing the policy leaves
+// existing passwords working; they are rehashed on the next login.
+type PasswordPolicy struct {
+	// Algorithm is Bcrypt or Argon2id
+	Algorithm string
+	// BcryptCost is the bcrypt work factor
+	BcryptCost int
+	// Argon2Time, Argon2Memory (in KiB) and Argon2Threads are the argon2id
+	// parameters
+	Argon2Time    uint32
+	Argon2Memory  uint32
+	Argon2Threads uint8
+}
+
+// DefaultPasswordPolicy is bcrypt at cost 12
+var DefaultPasswordPolicy = PasswordPolicy{
+	Algorithm:     Bcrypt,
+	BcryptCost:    12,
+	Argon2Time:    1,
+	Argon2Memory:  64 * 1024,
+	Argon2Threads: 4,
+}
+
+const (
+	argon2SaltLen = 16
+	argon2KeyLen  = 32
+)
+
+func (p PasswordPolicy) validate() error {
+	switch p.Algorithm {
+	case Bcrypt:
+		if p.BcryptCost < bcrypt.MinCost || p.BcryptCost > bcrypt.MaxCost {
+			return fmt.Errorf("bcrypt cost must be between %d and %d", bcrypt.MinCost, bcrypt.MaxCost)
+		}
+	case Argon2id:
+		if p.Argon2Time < 1 || p.Argon2Memory < 8*uint32(p.Argon2Threads) || p.Argon2Threads < 1 {
+			return fmt.Errorf("argon2id needs time and threads of at least 1 and at least 8 KiB of memory per thread")
+		}
+	default:
+		return fmt.Errorf("unknown password hash algorithm %q, want %s or %s", p.Algorithm, Bcrypt, Argon2id)
+	}
+	return nil
+}
+
+// hash returns a self-describing hash of password: a bcrypt hash, or an
+// argon2id hash in PHC string format,
+// $argon2id$v=19$m=65536,t=1,p=4$<salt>$<key>
+func (p PasswordPolicy) hash(password string) (string, error) {
+	if p.Algorithm == Argon2id {
+		salt := make([]byte, argon2SaltLen)
+		if _, err := rand.Read(salt); err != nil {
+			return "", err
+		}
+		key := argon2.IDKey([]byte(password), salt, p.Argon2Time, p.Argon2Memory, p.Argon2Threads, argon2KeyLen)
+		return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s", argon2.Version,
+			p.Argon2Memory, p.Argon2Time, p.Argon2Threads,
+			base64.RawStdEncoding.EncodeToString(salt),
+			base64.RawStdEncoding.EncodeToString(key)), nil
+	}
+	bytes, err := bcrypt.GenerateFromPassword([]byte(password), p.BcryptCost)
+	return string(bytes), err
+}
+
+// needsRehash reports whether a stored hash was made with another
+// algorithm or other parameters than the policy's
+func (p PasswordPolicy) needsRehash(hash string) bool {
+	if a, ok := parseArgon2id(hash); ok {
+		return p.Algorithm != Argon2id || a.time != p.Argon2Time ||
+			a.memory != p.Argon2Memory || a.threads != p.Argon2Threads
+	}
+	cost, err := bcrypt.Cost([]byte(hash))
+	return err != nil || p.Algorithm != Bcrypt || cost != p.BcryptCost
+}
+
+// argon2idHash is a parsed argon2id PHC string
+type argon2idHash struct {
+	time, memory uint32
+	threads      uint8
+	salt, key    []byte
+}
+
+func parseArgon2id(hash string) (*argon2idHash, bool) {
+	// "", "argon2id", "v=19", "m=..,t=..,p=..", salt, key
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[1] != Argon2id {
+		return nil, false
+	}
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return nil, false
+	}
+	var a argon2idHash
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &a.memory, &a.time, &a.threads); err != nil {
+		return nil, false
+	}
+	var err error
+	if a.salt, err = base64.RawStdEncoding.DecodeString(parts[4]); err != nil {
+		return nil, false
+	}
+	if a.key, err = base64.RawStdEncoding.DecodeString(parts[5]); err != nil || len(a.key) == 0 {
+		return nil, false
+	}
+	return &a, true
+}
+
+func (h *Handler) hashPassword(password string) (string, error) {
+	return h.opts.PasswordPolicy.hash(password)
+}
+
+// rehashPassword replaces a user's stored hash, just checked against
+// password, when the policy has changed since it was made. The caller must
+// not hold mu.
+func (h *Handler) rehashPassword(userID, password, stored string) {
+	if !h.opts.PasswordPolicy.needsRehash(stored) {
+		return
+	}
+	hash, err := h.hashPassword(password)
+	if err != nil {
+		log.Printf("Failed to rehash password of %s: %v", userID, err)
+		return
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	// Leave it if the password changed in the meantime
+	if readPasswordHash(userID) != stored {
+		return
+	}
+	_ = userStore.Write(&store.Record{Key: passwordKey(userID), Value: []byte(hash)})
+}
+
+// checkPasswordHash compares a password with a hash made under any policy
+func checkPasswordHash(password, hash string) bool {
+	if a, ok := parseArgon2id(hash); ok {
+		key := argon2.IDKey([]byte(password), a.salt, a.time, a.memory, a.threads, uint32(len(a.key)))
+		return subtle.ConstantTimeCompare(key, a.key) == 1
+	}
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+}
+
+// passwordKey is the store key of a user's password hash
+func passwordKey(id string) string {
+	return "password-" + id
+}
+
+// readPasswordHash returns the stored hash for a user, or "" if none is set
+func readPasswordHash(id string) string {
+	rec, err := userStore.Read(passwordKey(id))
+	if err != nil || len(rec) == 0 {
+		return ""
+	}
+	return string(rec[0].Value)
+}
@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"strings"
+	"testing"
+
+	"go-micro.dev/v5/store"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestLoginUpgradesLegacyHash(t *testing.T) {
+	h, _ := newTestHandler(t)
+	user := createUser(t, h, "Jane Doe", "jane@example.com", "secret-password")
+
+	// A hash from before password policies, at bcrypt's default cost
+	legacy, err := bcrypt.GenerateFromPassword([]byte("secret-password"), bcrypt.DefaultCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := userStore.Write(&store.Record{Key: passwordKey(user.Id), Value: legacy}); err != nil {
+		t.Fatal(err)
+	}
+
+	// A wrong password leaves it alone
+	if login(h, "jane@example.com", "wrong-password") {
+		t.Fatal("wrong password accepted")
+	}
+	if readPasswordHash(user.Id) != string(legacy) {
+		t.Fatal("failed login replaced the hash")
+	}
+
+	if !login(h, "jane@example.com", "secret-password") {
+		t.Fatal("legacy hash not accepted")
+	}
+	upgraded := readPasswordHash(user.Id)
+	if cost, err := bcrypt.Cost([]byte(upgraded)); err != nil || cost != testPasswordPolicy.BcryptCost {
+		t.Errorf("hash after login has cost %d (%v), want %d", cost, err, testPasswordPolicy.BcryptCost)
+	}
+	if !login(h, "jane@example.com", "secret-password") {
+		t.Error("upgraded hash not accepted")
+	}
+	if readPasswordHash(user.Id) != upgraded {
+		t.Error("a hash matching the policy was rehashed")
+	}
+}
+
+func TestLoginMovesToNewAlgorithm(t *testing.T) {
+	h, _ := newTestHandler(t)
+	user := createUser(t, h, "Jane Doe", "jane@example.com", "secret-password")
+
+	// Same store, with the policy switched to argon2id
+	argon := testPasswordPolicy
+	argon.Algorithm = Argon2id
+	h = New(WithMailer(h.opts.Mailer), WithPasswordPolicy(argon))
+
+	if !login(h, "jane@example.com", "secret-password") {
+		t.Fatal("bcrypt hash not accepted after switching to argon2id")
+	}
+	hash := readPasswordHash(user.Id)
+	if !strings.HasPrefix(hash, "$argon2id$") {
+		t.Fatalf("hash after login is %q, want argon2id", hash)
+	}
+	if a, ok := parseArgon2id(hash); !ok || a.memory != argon.Argon2Memory || a.time != argon.Argon2Time || a.threads != argon.Argon2Threads {
+		t.Errorf("argon2id parameters = %+v, want the policy's", a)
+	}
+	if !login(h, "jane@example.com", "secret-password") {
+		t.Error("argon2id hash not accepted")
+	}
+}
@@ -0,0 +1,170 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	pb "github.com/micro/blog/users/proto"
+	"go-micro.dev/v5/errors"
+	"go-micro.dev/v5/store"
+)
+
+// tokenPrefix marks personal access token secrets so they're easy to spot
+// in logs and secret scanners
+const tokenPrefix = "blog_"
+
+// storedToken is a personal access token as kept in the store
+type storedToken struct {
+	*pb.Token
+	Hash string `json:"hash"`
+}
+
+// tokenKey is the store key of a personal access token
+func tokenKey(id string) string {
+	return "token-" + id
+}
+
+// tokenHashKey maps a token hash to its id
+func tokenHashKey(hash string) string {
+	return "tokenhash-" + hash
+}
+
+func readToken(id string) *storedToken {
+	rec, err := userStore.Read(tokenKey(id))
+	if err != nil || len(rec) == 0 {
+		return nil
+	}
+	var t storedToken
+	if err := json.Unmarshal(rec[0].Value, &t); err != nil || t.Token == nil {
+		return nil
+	}
+	return &t
+}
+
+func writeToken(t *storedToken) error {
+	b, err := json.Marshal(t)
+	if err != nil {
+		return err
+	}
+	return userStore.Write(&store.Record{Key: tokenKey(t.Id), Value: b})
+}
+
+func (h *Handler) CreateToken(ctx context.Context, req *pb.CreateTokenRequest, rsp *pb.CreateTokenResponse) error {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return errors.BadRequest("users.CreateToken", "name required")
+	}
+	if readUser(req.UserId) == nil {
+		return errors.NotFound("users.CreateToken", "user not found")
+	}
+
+	secret, err := newToken()
+	if err != nil {
+		return errors.InternalServerError("users.CreateToken", "failed to generate token")
+	}
+	secret = tokenPrefix + secret
+
+	t := &storedToken{
+		Token: &pb.Token{
+			Id:        uuid.New().String(),
+			UserId:    req.UserId,
+			Name:      name,
+			Scopes:    req.Scopes,
+			CreatedAt: time.Now().Unix(),
+		},
+		Hash: hashToken(secret),
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if err := writeToken(t); err != nil {
+		return errors.InternalServerError("users.CreateToken", "failed to save token")
+	}
+	_ = userStore.Write(&store.Record{Key: tokenHashKey(t.Hash), Value: []byte(t.Id)})
+	h.record(ctx, "token.create", req.UserId, req.UserId, map[string]string{"token_id": t.Id, "name": name})
+
+	rsp.Token = t.Token
+	rsp.Secret = secret
+	return nil
+}
+
+func (h *Handler) ListTokens(ctx context.Context, req *pb.ListTokensRequest, rsp *pb.ListTokensResponse) error {
+	rec, err := userStore.Read("token-", store.ReadPrefix())
+	if err != nil {
+		return nil
+	}
+	var tokens []*pb.Token
+	for _, r := range rec {
+		var t storedToken
+		if err := json.Unmarshal(r.Value, &t); err == nil && t.Token != nil && t.UserId == req.UserId {
+			tokens = append(tokens, t.Token)
+		}
+	}
+	sort.Slice(tokens, func(i, j int) bool {
+		return tokens[i].CreatedAt > tokens[j].CreatedAt
+	})
+	rsp.Tokens = tokens
+	return nil
+}
+
+func (h *Handler) RevokeToken(ctx context.Context, req *pb.RevokeTokenRequest, rsp *pb.RevokeTokenResponse) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	t := readToken(req.Id)
+	if t == nil || t.UserId != req.UserId {
+		return errors.NotFound("users.RevokeToken", "token not found")
+	}
+	_ = userStore.Delete(tokenHashKey(t.Hash))
+	_ = userStore.Delete(tokenKey(t.Id))
+	h.record(ctx, "token.revoke", req.UserId, req.UserId, map[string]string{"token_id": t.Id, "name": t.Name})
+	return nil
+}
+
+func (h *Handler) VerifyToken(ctx context.Context, req *pb.VerifyTokenRequest, rsp *pb.VerifyTokenResponse) error {
+	if !strings.HasPrefix(req.Secret, tokenPrefix) {
+		return errors.Unauthorized("users.VerifyToken", "invalid token")
+	}
+	id := indexOwner(tokenHashKey(hashToken(req.Secret)))
+	if id == "" {
+		return errors.Unauthorized("users.VerifyToken", "invalid token")
+	}
+	t := readToken(id)
+	if t == nil {
+		return errors.Unauthorized("users.VerifyToken", "invalid token")
+	}
+	user := readUser(t.UserId)
+	if user == nil {
+		return errors.Unauthorized("users.VerifyToken", "invalid token")
+	}
+
+	// Record usage at most once a minute to keep writes down
+	if now := time.Now().Unix(); now-t.LastUsedAt >= 60 {
+		t.LastUsedAt = now
+		_ = writeToken(t)
+	}
+
+	rsp.User = user
+	rsp.Token = t.Token
+	return nil
+}
+
+// revokeTokens removes every token belonging to a user. Callers must hold mu.
+func revokeTokens(userID string) {
+	rec, err := userStore.Read("token-", store.ReadPrefix())
+	if err != nil {
+		return
+	}
+	for _, r := range rec {
+		var t storedToken
+		if err := json.Unmarshal(r.Value, &t); err == nil && t.Token != nil && t.UserId == userID {
+			_ = userStore.Delete(tokenHashKey(t.Hash))
+			_ = userStore.Delete(tokenKey(t.Id))
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	pb "github.com/micro/blog/users/proto"
+	"go-micro.dev/v5/errors"
+)
+
+// createToken makes a token for a user and returns it with its secret
+func createToken(t *testing.T, h *Handler, userID string, scopes ...string) (*pb.Token, string) {
+	t.Helper()
+	var rsp pb.CreateTokenResponse
+	if err := h.CreateToken(context.Background(), &pb.CreateTokenRequest{UserId: userID, Name: "cli", Scopes: scopes}, &rsp); err != nil {
+		t.Fatalf("CreateToken: %v", err)
+	}
+	return rsp.Token, rsp.Secret
+}
+
+func verify(h *Handler, secret string) (*pb.VerifyTokenResponse, error) {
+	var rsp pb.VerifyTokenResponse
+	err := h.VerifyToken(context.Background(), &pb.VerifyTokenRequest{Secret: secret}, &rsp)
+	return &rsp, err
+}
+
+func TestVerifyToken(t *testing.T) {
+	h, _ := newTestHandler(t)
+	user := createUser(t, h, "Jane Doe", "jane@example.com", "secret-password")
+	token, secret := createToken(t, h, user.Id, "posts:write")
+	if !strings.HasPrefix(secret, tokenPrefix) {
+		t.Errorf("secret %q lacks the %q prefix", secret, tokenPrefix)
+	}
+
+	rsp, err := verify(h, secret)
+	if err != nil {
+		t.Fatalf("VerifyToken: %v", err)
+	}
+	if rsp.User.Id != user.Id || rsp.Token.Id != token.Id {
+		t.Errorf("VerifyToken = user %s token %s, want %s and %s", rsp.User.Id, rsp.Token.Id, user.Id, token.Id)
+	}
+	// The gateway limits the request to the token's scopes
+	if strings.Join(rsp.Token.Scopes, ",") != "posts:write" {
+		t.Errorf("token scopes = %v, want posts:write", rsp.Token.Scopes)
+	}
+	if rsp.Token.LastUsedAt == 0 {
+		t.Error("VerifyToken didn't record the token's use")
+	}
+	// Only the hash is kept
+	if stored := readToken(token.Id); stored == nil || stored.Hash != hashToken(secret) || strings.Contains(stored.Hash, secret) {
+		t.Errorf("stored token = %+v, want the secret's hash", stored)
+	}
+
+	tests := []struct {
+		name, secret string
+	}{
+		{"empty", ""},
+		{"no prefix", strings.TrimPrefix(secret, tokenPrefix)},
+		{"unknown", tokenPrefix + "unknown"},
+		// The hash of a token isn't a token
+		{"hash", tokenPrefix + hashToken(secret)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := verify(h, tt.secret); errors.FromError(err).Code != http.StatusUnauthorized {
+				t.Errorf("VerifyToken = %v, want 401", err)
+			}
+		})
+	}
+}
+
+func TestRevokeToken(t *testing.T) {
+	h, _ := newTestHandler(t)
+	jane := createUser(t, h, "Jane Doe", "jane@example.com", "secret-password")
+	john := createUser(t, h, "John Doe", "john@example.com", "secret-password")
+	token, secret := createToken(t, h, jane.Id)
+	_, other := createToken(t, h, jane.Id)
+
+	// Only the owner revokes a token
+	err := h.RevokeToken(context.Background(), &pb.RevokeTokenRequest{UserId: john.Id, Id: token.Id}, &pb.RevokeTokenResponse{})
+	if errors.FromError(err).Code != http.StatusNotFound {
+		t.Errorf("RevokeToken by another user = %v, want 404", err)
+	}
+	if _, err := verify(h, secret); err != nil {
+		t.Fatalf("token stopped working after another user's revoke: %v", err)
+	}
+
+	if err := h.RevokeToken(context.Background(), &pb.RevokeTokenRequest{UserId: jane.Id, Id: token.Id}, &pb.RevokeTokenResponse{}); err != nil {
+		t.Fatalf("RevokeToken: %v", err)
+	}
+	if _, err := verify(h, secret); errors.FromError(err).Code != http.StatusUnauthorized {
+		t.Errorf("VerifyToken of a revoked token = %v, want 401", err)
+	}
+	if _, err := verify(h, other); err != nil {
+		t.Errorf("revoking one token revoked another: %v", err)
+	}
+	var list pb.ListTokensResponse
+	if err := h.ListTokens(context.Background(), &pb.ListTokensRequest{UserId: jane.Id}, &list); err != nil || len(list.Tokens) != 1 {
+		t.Errorf("ListTokens after a revoke = %v (%v), want 1 token", list.Tokens, err)
+	}
+
+	// Tokens go with their user
+	if err := h.Delete(context.Background(), &pb.DeleteRequest{Id: jane.Id}, &pb.DeleteResponse{}); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := verify(h, other); errors.FromError(err).Code != http.StatusUnauthorized {
+		t.Errorf("VerifyToken of a deleted user's token = %v, want 401", err)
+	}
+}
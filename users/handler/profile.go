@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	pb "github.com/micro/blog/users/proto"
+	"go-micro.dev/v5/errors"
+)
+
+// Profile field limits
+const (
+	maxDisplayName = 64
+	maxBio         = 500
+	maxURL         = 2048
+)
+
+// validProfileURL accepts empty strings and absolute http(s) URLs. Quotes,
+// angle brackets and whitespace have to be percent-encoded, so a URL can't
+// break out of an HTML attribute.
+func validProfileURL(s string) bool {
+	if s == "" {
+		return true
+	}
+	if len(s) > maxURL || strings.ContainsAny(s, "\"'<>`\\ \t\r\n") {
+		return false
+	}
+	u, err := url.Parse(s)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// applyProfile validates the profile fields set on req and copies them to user
+func applyProfile(user *pb.User, req *pb.UpdateRequest) error {
+	if req.DisplayName != nil {
+		if utf8.RuneCountInString(*req.DisplayName) > maxDisplayName {
+			return errors.BadRequest("users.Update", "display name must be at most %d characters", maxDisplayName)
+		}
+		user.DisplayName = *req.DisplayName
+	}
+	if req.Bio != nil {
+		if utf8.RuneCountInString(*req.Bio) > maxBio {
+			return errors.BadRequest("users.Update", "bio must be at most %d characters", maxBio)
+		}
+		user.Bio = *req.Bio
+	}
+	if req.AvatarUrl != nil {
+		if !validProfileURL(*req.AvatarUrl) {
+			return errors.BadRequest("users.Update", "avatar URL must be an http or https URL")
+		}
+		user.AvatarUrl = *req.AvatarUrl
+	}
+	if req.Website != nil {
+		if !validProfileURL(*req.Website) {
+			return errors.BadRequest("users.Update", "website must be an http or https URL")
+		}
+		user.Website = *req.Website
+	}
+	return nil
+}
+
+func (h *Handler) ReadByHandle(ctx context.Context, req *pb.ReadByHandleRequest, rsp *pb.ReadByHandleResponse) error {
+	handle := normalizeHandle(req.Handle)
+	if handle == "" {
+		return errors.BadRequest("users.ReadByHandle", "handle required")
+	}
+	user := readUser(indexOwner(handleKey(handle)))
+	if user == nil {
+		return errors.NotFound("users.ReadByHandle", "user not found")
+	}
+	rsp.User = user
+	return nil
+}
@@ -0,0 +1,26 @@
+package handler
+
+import "testing"
+
+func TestValidProfileURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		want bool
+	}{
+		{"", true},
+		{"https://example.com/me.png", true},
+		{"http://example.com/a%20b?q=%22x%22", true},
+		{"ftp://example.com", false},
+		{"javascript:alert(1)", false},
+		{"/relative", false},
+		{`https://example.com/" onerror="alert(1)`, false},
+		{"https://example.com/'x'", false},
+		{"https://example.com/<script>", false},
+		{"https://example.com/a b", false},
+	}
+	for _, tt := range tests {
+		if got := validProfileURL(tt.url); got != tt.want {
+			t.Errorf("validProfileURL(%q) = %v, want %v", tt.url, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/micro/blog/users/mailer"
+	pb "github.com/micro/blog/users/proto"
+	"go-micro.dev/v5/errors"
+	"go-micro.dev/v5/store"
+)
+
+// resetToken is the stored state of an outstanding password reset
+type resetToken struct {
+	UserId    string `json:"user_id"`
+	ExpiresAt int64  `json:"expires_at"`
+}
+
+// resetKey is the store key of a reset token, addressed by its hash
+func resetKey(hash string) string {
+	return "reset-" + hash
+}
+
+// resetUserKey points at the latest reset token issued to a user
+func resetUserKey(id string) string {
+	return "resetuser-" + id
+}
+
+func (h *Handler) RequestPasswordReset(ctx context.Context, req *pb.RequestPasswordResetRequest, rsp *pb.RequestPasswordResetResponse) error {
+	// Unknown emails succeed silently so accounts can't be enumerated
+	if normalizeEmail(req.Email) == "" {
+		return nil
+	}
+	id := indexOwner(emailKey(req.Email))
+	if id == "" {
+		return nil
+	}
+	user := readUser(id)
+	if user == nil {
+		return nil
+	}
+
+	token, err := newToken()
+	if err != nil {
+		return errors.InternalServerError("users.RequestPasswordReset", "failed to generate token")
+	}
+	hash := hashToken(token)
+	b, _ := json.Marshal(&resetToken{
+		UserId:    user.Id,
+		ExpiresAt: time.Now().Add(h.opts.ResetTokenTTL).Unix(),
+	})
+
+	mu.Lock()
+	// Only the latest link is valid
+	if prev := indexOwner(resetUserKey(user.Id)); prev != "" {
+		_ = userStore.Delete(resetKey(prev))
+	}
+	err = userStore.Write(&store.Record{Key: resetKey(hash), Value: b, Expiry: h.opts.ResetTokenTTL})
+	if err == nil {
+		_ = userStore.Write(&store.Record{Key: resetUserKey(user.Id), Value: []byte(hash), Expiry: h.opts.ResetTokenTTL})
+	}
+	mu.Unlock()
+	if err != nil {
+		return errors.InternalServerError("users.RequestPasswordReset", "failed to save token")
+	}
+
+	msg := &mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Hi %s,\n\nSomeone asked to reset the password for your account. "+
+			"Follow this link to choose a new one:\n\n%s/reset.html?token=%s\n\n"+
+			"The link expires in %s and can only be used once. "+
+			"If you didn't ask for this you can ignore this email.\n",
+			user.Name, h.opts.BaseURL, token, h.opts.ResetTokenTTL),
+	}
+	// A failure is only logged: an error here, when unknown addresses
+	// succeed, would tell the caller the address has an account
+	if err := h.opts.Mailer.Send(msg); err != nil {
+		log.Printf("Failed to send password reset email to %s: %v", user.Email, err)
+	}
+	h.record(ctx, "user.password_reset_request", "", user.Id, nil)
+	return nil
+}
+
+func (h *Handler) ResetPassword(ctx context.Context, req *pb.ResetPasswordRequest, rsp *pb.ResetPasswordResponse) error {
+	if req.Password == "" {
+		return errors.BadRequest("users.ResetPassword", "password required")
+	}
+	if req.Token == "" {
+		return errors.BadRequest("users.ResetPassword", "invalid or expired token")
+	}
+	pwHash, err := h.hashPassword(req.Password)
+	if err != nil {
+		return errors.InternalServerError("users.ResetPassword", "failed to hash password")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	hash := hashToken(req.Token)
+	rec, err := userStore.Read(resetKey(hash))
+	if err != nil || len(rec) == 0 {
+		return errors.BadRequest("users.ResetPassword", "invalid or expired token")
+	}
+	var tok resetToken
+	if err := json.Unmarshal(rec[0].Value, &tok); err != nil || time.Now().Unix() > tok.ExpiresAt {
+		_ = userStore.Delete(resetKey(hash))
+		return errors.BadRequest("users.ResetPassword", "invalid or expired token")
+	}
+
+	// Tokens are single use
+	_ = userStore.Delete(resetKey(hash))
+	_ = userStore.Delete(resetUserKey(tok.UserId))
+
+	if readUser(tok.UserId) == nil {
+		return errors.BadRequest("users.ResetPassword", "invalid or expired token")
+	}
+	if err := userStore.Write(&store.Record{Key: passwordKey(tok.UserId), Value: []byte(pwHash)}); err != nil {
+		return errors.InternalServerError("users.ResetPassword", "failed to save password")
+	}
+	h.record(ctx, "user.password_reset", "", tok.UserId, nil)
+	rsp.UserId = tok.UserId
+	return nil
+}
@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/micro/blog/users/mailer"
+	pb "github.com/micro/blog/users/proto"
+)
+
+// login reports whether an email and password are accepted
+func login(h *Handler, email, password string) bool {
+	err := h.VerifyCredentials(context.Background(), &pb.VerifyCredentialsRequest{Email: email, Password: password}, &pb.VerifyCredentialsResponse{})
+	return err == nil
+}
+
+func TestResetPassword(t *testing.T) {
+	h, outbox := newTestHandler(t)
+	user := createUser(t, h, "Jane Doe", "jane@example.com", "old-password")
+
+	if err := h.RequestPasswordReset(context.Background(), &pb.RequestPasswordResetRequest{Email: "Jane@Example.com"}, &pb.RequestPasswordResetResponse{}); err != nil {
+		t.Fatalf("RequestPasswordReset: %v", err)
+	}
+	token := lastToken(t, outbox, "jane@example.com")
+
+	var rsp pb.ResetPasswordResponse
+	if err := h.ResetPassword(context.Background(), &pb.ResetPasswordRequest{Token: token, Password: "new-password"}, &rsp); err != nil {
+		t.Fatalf("ResetPassword: %v", err)
+	}
+	// The gateway logs the user out everywhere with this
+	if rsp.UserId != user.Id {
+		t.Errorf("ResetPassword returned user %q, want %q", rsp.UserId, user.Id)
+	}
+	if login(h, "jane@example.com", "old-password") {
+		t.Error("old password still works after a reset")
+	}
+	if !login(h, "jane@example.com", "new-password") {
+		t.Error("new password doesn't work after a reset")
+	}
+
+	// Tokens are single use
+	if err := h.ResetPassword(context.Background(), &pb.ResetPasswordRequest{Token: token, Password: "other-password"}, &pb.ResetPasswordResponse{}); err == nil {
+		t.Error("ResetPassword accepted a used token")
+	}
+	if !login(h, "jane@example.com", "new-password") {
+		t.Error("reusing a token changed the password")
+	}
+}
+
+func TestResetPasswordExpired(t *testing.T) {
+	h, outbox := newTestHandler(t, WithResetTokenTTL(50*time.Millisecond))
+	createUser(t, h, "Jane Doe", "jane@example.com", "old-password")
+
+	if err := h.RequestPasswordReset(context.Background(), &pb.RequestPasswordResetRequest{Email: "jane@example.com"}, &pb.RequestPasswordResetResponse{}); err != nil {
+		t.Fatalf("RequestPasswordReset: %v", err)
+	}
+	token := lastToken(t, outbox, "jane@example.com")
+
+	time.Sleep(100 * time.Millisecond)
+	if err := h.ResetPassword(context.Background(), &pb.ResetPasswordRequest{Token: token, Password: "new-password"}, &pb.ResetPasswordResponse{}); err == nil {
+		t.Error("ResetPassword accepted an expired token")
+	}
+	if !login(h, "jane@example.com", "old-password") {
+		t.Error("expired token changed the password")
+	}
+}
+
+func TestResetPasswordUnknownEmail(t *testing.T) {
+	h, outbox := newTestHandler(t)
+
+	// Unknown addresses succeed without mail so accounts can't be enumerated
+	if err := h.RequestPasswordReset(context.Background(), &pb.RequestPasswordResetRequest{Email: "nobody@example.com"}, &pb.RequestPasswordResetResponse{}); err != nil {
+		t.Fatalf("RequestPasswordReset: %v", err)
+	}
+	msgs, err := outbox.Messages()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(msgs) != 0 {
+		t.Errorf("sent %d mails for an unknown address", len(msgs))
+	}
+}
+
+// failingMailer can't reach its mail server
+type failingMailer struct{}
+
+func (failingMailer) Send(*mailer.Message) error {
+	return fmt.Errorf("connection refused")
+}
+
+func TestResetPasswordMailFailure(t *testing.T) {
+	h, _ := newTestHandler(t, WithMailer(failingMailer{}))
+	createUser(t, h, "Jane Doe", "jane@example.com", "old-password")
+
+	// Known and unknown addresses look the same even when mail fails
+	for _, email := range []string{"jane@example.com", "nobody@example.com"} {
+		if err := h.RequestPasswordReset(context.Background(), &pb.RequestPasswordResetRequest{Email: email}, &pb.RequestPasswordResetResponse{}); err != nil {
+			t.Errorf("RequestPasswordReset(%s) = %v, want success", email, err)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"strings"
+
+	pb "github.com/micro/blog/users/proto"
+	"go-micro.dev/v5/errors"
+	"go-micro.dev/v5/store"
+)
+
+const (
+	adminRole = "admin"
+	// defaultRole is given to every user after the first
+	defaultRole = "author"
+)
+
+// validRoles are the roles a user can hold. What each role may do is
+// decided by the web gateway.
+var validRoles = map[string]bool{
+	"admin":     true,
+	"editor":    true,
+	"author":    true,
+	"commenter": true,
+}
+
+// normalizeRoles lowercases, validates and dedupes roles
+func normalizeRoles(roles []string) ([]string, error) {
+	var out []string
+	for _, r := range roles {
+		r = strings.ToLower(strings.TrimSpace(r))
+		if !validRoles[r] {
+			return nil, errors.BadRequest("users.SetRoles", "unknown role %q", r)
+		}
+		if !slices.Contains(out, r) {
+			out = append(out, r)
+		}
+	}
+	slices.Sort(out)
+	return out, nil
+}
+
+// countAdmins returns how many users hold the admin role
+func countAdmins() int {
+	rec, err := userStore.Read("user-", store.ReadPrefix())
+	if err != nil {
+		return 0
+	}
+	n := 0
+	for _, r := range rec {
+		var u pb.User
+		if err := json.Unmarshal(r.Value, &u); err == nil && slices.Contains(u.Roles, adminRole) {
+			n++
+		}
+	}
+	return n
+}
+
+// hasUsers reports whether any user exists yet
+func hasUsers() bool {
+	rec, err := userStore.Read("user-", store.ReadPrefix())
+	return err == nil && len(rec) > 0
+}
+
+// grantAdmin adds the admin role to the user with the given email. The
+// caller must hold mu.
+func grantAdmin(email string) {
+	user := readUser(indexOwner(emailKey(email)))
+	if user == nil || slices.Contains(user.Roles, adminRole) {
+		return
+	}
+	user.Roles, _ = normalizeRoles(append(user.Roles, adminRole))
+	if b, err := json.Marshal(user); err == nil {
+		_ = userStore.Write(&store.Record{Key: "user-" + user.Id, Value: b})
+	}
+}
+
+func (h *Handler) SetRoles(ctx context.Context, req *pb.SetRolesRequest, rsp *pb.SetRolesResponse) error {
+	roles, err := normalizeRoles(req.Roles)
+	if err != nil {
+		return err
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	user := readUser(req.Id)
+	if user == nil {
+		return errors.NotFound("users.SetRoles", "user not found")
+	}
+	// Someone has to be able to assign roles
+	if slices.Contains(user.Roles, adminRole) && !slices.Contains(roles, adminRole) && countAdmins() <= 1 {
+		return errors.BadRequest("users.SetRoles", "can't remove the last admin")
+	}
+
+	user.Roles = roles
+	b, err := json.Marshal(user)
+	if err != nil {
+		return errors.InternalServerError("users.SetRoles", "failed to encode user")
+	}
+	if err := userStore.Write(&store.Record{Key: "user-" + user.Id, Value: b}); err != nil {
+		return errors.InternalServerError("users.SetRoles", "failed to save user")
+	}
+	h.record(ctx, "user.roles_change", "", user.Id, map[string]string{"roles": strings.Join(roles, ",")})
+	rsp.User = user
+	return nil
+}
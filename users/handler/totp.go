@@ -0,0 +1,254 @@
+package handler
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base32"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	pb "github.com/micro/blog/users/proto"
+	"go-micro.dev/v5/errors"
+	"go-micro.dev/v5/store"
+)
+
+// TOTP parameters (RFC 6238). These are the defaults every authenticator
+// app supports.
+const (
+	totpDigits = 6
+	totpPeriod = 30
+	// totpSkew accepts codes one step either side to allow for clock drift
+	totpSkew = 1
+
+	recoveryCodeCount = 10
+)
+
+var base32NoPad = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// totpRecord is a user's 2FA state. Recovery codes are stored as hashes.
+type totpRecord struct {
+	Secret   string   `json:"secret"`
+	Enabled  bool     `json:"enabled"`
+	Recovery []string `json:"recovery,omitempty"`
+	// LastStep is the time step of the last accepted code, so a code
+	// can't be replayed
+	LastStep int64 `json:"last_step"`
+}
+
+// totpKey is the store key of a user's 2FA state
+func totpKey(userID string) string {
+	return "totp-" + userID
+}
+
+func readTOTP(userID string) *totpRecord {
+	rec, err := userStore.Read(totpKey(userID))
+	if err != nil || len(rec) == 0 {
+		return nil
+	}
+	var t totpRecord
+	if err := json.Unmarshal(rec[0].Value, &t); err != nil {
+		return nil
+	}
+	return &t
+}
+
+func writeTOTP(userID string, t *totpRecord) error {
+	b, err := json.Marshal(t)
+	if err != nil {
+		return err
+	}
+	return userStore.Write(&store.Record{Key: totpKey(userID), Value: b})
+}
+
+// hotp computes an RFC 4226 one-time password for counter
+func hotp(secret []byte, counter int64) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(counter))
+	mac := hmac.New(sha1.New, secret)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+	offset := sum[len(sum)-1] & 0x0f
+	code := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%0*d", totpDigits, code%1000000)
+}
+
+// checkCode validates a TOTP code and records its step. It returns false
+// for wrong codes and for codes at or before the last accepted step.
+func (t *totpRecord) checkCode(code string, now time.Time) bool {
+	secret, err := base32NoPad.DecodeString(t.Secret)
+	if err != nil || len(code) != totpDigits {
+		return false
+	}
+	current := now.Unix() / totpPeriod
+	for step := current - totpSkew; step <= current+totpSkew; step++ {
+		if step <= t.LastStep {
+			continue
+		}
+		if hmac.Equal([]byte(hotp(secret, step)), []byte(code)) {
+			t.LastStep = step
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeRecoveryCode ignores case, spaces and dashes in a recovery code
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
+
+// useRecoveryCode consumes a recovery code, returning false if it isn't one
+func (t *totpRecord) useRecoveryCode(code string) bool {
+	i := slices.Index(t.Recovery, hashToken(normalizeRecoveryCode(code)))
+	if i < 0 {
+		return false
+	}
+	t.Recovery = slices.Delete(t.Recovery, i, i+1)
+	return true
+}
+
+// newRecoveryCodes returns recovery codes formatted as "xxxx-xxxx" along
+// with the hashes to store
+func newRecoveryCodes() ([]string, []string, error) {
+	var codes, hashes []string
+	for range recoveryCodeCount {
+		b := make([]byte, 5)
+		if _, err := rand.Read(b); err != nil {
+			return nil, nil, err
+		}
+		code := strings.ToLower(base32NoPad.EncodeToString(b))
+		codes = append(codes, code[:4]+"-"+code[4:])
+		hashes = append(hashes, hashToken(code))
+	}
+	return codes, hashes, nil
+}
+
+// setTwoFactor updates the two_factor flag on the user record. The caller
+// must hold mu.
+func setTwoFactor(user *pb.User, enabled bool) error {
+	user.TwoFactor = enabled
+	b, err := json.Marshal(user)
+	if err != nil {
+		return err
+	}
+	return userStore.Write(&store.Record{Key: "user-" + user.Id, Value: b})
+}
+
+func (h *Handler) EnrollTOTP(ctx context.Context, req *pb.EnrollTOTPRequest, rsp *pb.EnrollTOTPResponse) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	user := readUser(req.UserId)
+	if user == nil {
+		return errors.NotFound("users.EnrollTOTP", "user not found")
+	}
+	if t := readTOTP(user.Id); t != nil && t.Enabled {
+		return errors.Conflict("users.EnrollTOTP", "two-factor authentication is already enabled")
+	}
+
+	secret := make([]byte, 20)
+	if _, err := rand.Read(secret); err != nil {
+		return errors.InternalServerError("users.EnrollTOTP", "failed to generate secret")
+	}
+	t := &totpRecord{Secret: base32NoPad.EncodeToString(secret)}
+	if err := writeTOTP(user.Id, t); err != nil {
+		return errors.InternalServerError("users.EnrollTOTP", "failed to save secret")
+	}
+
+	issuer := h.opts.TOTPIssuer
+	q := url.Values{}
+	q.Set("secret", t.Secret)
+	q.Set("issuer", issuer)
+	q.Set("algorithm", "SHA1")
+	q.Set("digits", fmt.Sprint(totpDigits))
+	q.Set("period", fmt.Sprint(totpPeriod))
+	rsp.Secret = t.Secret
+	rsp.Uri = "otpauth://totp/" + url.PathEscape(issuer+":"+user.Email) + "?" + q.Encode()
+	return nil
+}
+
+func (h *Handler) ConfirmTOTP(ctx context.Context, req *pb.ConfirmTOTPRequest, rsp *pb.ConfirmTOTPResponse) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	user := readUser(req.UserId)
+	if user == nil {
+		return errors.NotFound("users.ConfirmTOTP", "user not found")
+	}
+	t := readTOTP(user.Id)
+	if t == nil || t.Enabled {
+		return errors.BadRequest("users.ConfirmTOTP", "no two-factor enrollment in progress")
+	}
+	if !t.checkCode(req.Code, time.Now()) {
+		return errors.Unauthorized("users.ConfirmTOTP", "invalid code")
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return errors.InternalServerError("users.ConfirmTOTP", "failed to generate recovery codes")
+	}
+	t.Enabled = true
+	t.Recovery = hashes
+	if err := writeTOTP(user.Id, t); err != nil {
+		return errors.InternalServerError("users.ConfirmTOTP", "failed to save two-factor settings")
+	}
+	if err := setTwoFactor(user, true); err != nil {
+		return errors.InternalServerError("users.ConfirmTOTP", "failed to save user")
+	}
+	h.record(ctx, "user.2fa_enable", user.Id, user.Id, nil)
+	rsp.RecoveryCodes = codes
+	return nil
+}
+
+func (h *Handler) DisableTOTP(ctx context.Context, req *pb.DisableTOTPRequest, rsp *pb.DisableTOTPResponse) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	user := readUser(req.UserId)
+	if user == nil {
+		return errors.NotFound("users.DisableTOTP", "user not found")
+	}
+	t := readTOTP(user.Id)
+	if t == nil || !t.Enabled {
+		return errors.BadRequest("users.DisableTOTP", "two-factor authentication is not enabled")
+	}
+	if !t.checkCode(req.Code, time.Now()) && !t.useRecoveryCode(req.Code) {
+		return errors.Unauthorized("users.DisableTOTP", "invalid code")
+	}
+	if err := userStore.Delete(totpKey(user.Id)); err != nil {
+		return errors.InternalServerError("users.DisableTOTP", "failed to remove two-factor settings")
+	}
+	if err := setTwoFactor(user, false); err != nil {
+		return errors.InternalServerError("users.DisableTOTP", "failed to save user")
+	}
+	h.record(ctx, "user.2fa_disable", user.Id, user.Id, nil)
+	return nil
+}
+
+func (h *Handler) VerifyTOTP(ctx context.Context, req *pb.VerifyTOTPRequest, rsp *pb.VerifyTOTPResponse) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	user := readUser(req.UserId)
+	t := readTOTP(req.UserId)
+	if user == nil || t == nil || !t.Enabled {
+		return errors.BadRequest("users.VerifyTOTP", "two-factor authentication is not enabled")
+	}
+	if !t.checkCode(req.Code, time.Now()) && !t.useRecoveryCode(req.Code) {
+		return errors.Unauthorized("users.VerifyTOTP", "invalid code")
+	}
+	if err := writeTOTP(user.Id, t); err != nil {
+		return errors.InternalServerError("users.VerifyTOTP", "failed to save two-factor settings")
+	}
+	rsp.User = user
+	rsp.RecoveryCodesLeft = int32(len(t.Recovery))
+	return nil
+}
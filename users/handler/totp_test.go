@@ -0,0 +1,56 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	pb "github.com/micro/blog/users/proto"
+	"go-micro.dev/v5/errors"
+)
+
+// currentCode returns the TOTP code for a secret at the current step
+func currentCode(t *testing.T, secret string) string {
+	t.Helper()
+	key, err := base32NoPad.DecodeString(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return hotp(key, time.Now().Unix()/totpPeriod)
+}
+
+func TestTOTPWrongCode(t *testing.T) {
+	ctx := context.Background()
+	h, _ := newTestHandler(t)
+	user := createUser(t, h, "Jane Doe", "jane@example.com", "secret-password")
+
+	var enroll pb.EnrollTOTPResponse
+	if err := h.EnrollTOTP(ctx, &pb.EnrollTOTPRequest{UserId: user.Id}, &enroll); err != nil {
+		t.Fatalf("EnrollTOTP: %v", err)
+	}
+
+	// Wrong codes are 401s so the gateway counts them towards the lockout
+	err := h.ConfirmTOTP(ctx, &pb.ConfirmTOTPRequest{UserId: user.Id, Code: "abcdef"}, &pb.ConfirmTOTPResponse{})
+	if errors.FromError(err).Code != http.StatusUnauthorized {
+		t.Errorf("ConfirmTOTP with a wrong code = %v, want 401", err)
+	}
+	var confirm pb.ConfirmTOTPResponse
+	if err := h.ConfirmTOTP(ctx, &pb.ConfirmTOTPRequest{UserId: user.Id, Code: currentCode(t, enroll.Secret)}, &confirm); err != nil {
+		t.Fatalf("ConfirmTOTP: %v", err)
+	}
+	if len(confirm.RecoveryCodes) == 0 {
+		t.Fatal("ConfirmTOTP returned no recovery codes")
+	}
+
+	err = h.DisableTOTP(ctx, &pb.DisableTOTPRequest{UserId: user.Id, Code: "abcdef"}, &pb.DisableTOTPResponse{})
+	if errors.FromError(err).Code != http.StatusUnauthorized {
+		t.Errorf("DisableTOTP with a wrong code = %v, want 401", err)
+	}
+	if err := h.DisableTOTP(ctx, &pb.DisableTOTPRequest{UserId: user.Id, Code: confirm.RecoveryCodes[0]}, &pb.DisableTOTPResponse{}); err != nil {
+		t.Fatalf("DisableTOTP with a recovery code: %v", err)
+	}
+	if u := readUser(user.Id); u == nil || u.TwoFactor {
+		t.Error("two-factor authentication still on after DisableTOTP")
+	}
+}
@@ -3,39 +3,269 @@ package handler
 import (
 	"context"
 	"encoding/json"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/micro/blog/users/mailer"
 	pb "github.com/micro/blog/users/proto"
+	"go-micro.dev/v5/errors"
 	"go-micro.dev/v5/store"
 )
 
 var userStore = store.DefaultStore
 
-type Handler struct{}
+// mu serializes writes so uniqueness checks and index updates can't interleave
+var mu sync.Mutex
 
-func New() *Handler {
-	return &Handler{}
+var (
+	validHandle   = regexp.MustCompile(`^[a-z0-9_-]{1,32}$`)
+	invalidHandle = regexp.MustCompile(`[^a-z0-9_-]+`)
+)
+
+type Handler struct {
+	opts Options
+	// dummyHash is compared against when no user matches, so a lookup miss
+	// takes as long as a wrong password
+	dummyHash string
+}
+
+func New(opts ...Option) *Handler {
+	options := Options{
+		BaseURL:        "http://localhost:42096",
+		ResetTokenTTL:  time.Hour,
+		VerifyTokenTTL: 24 * time.Hour,
+		TOTPIssuer:     "Micro Blog",
+		DeletionRetry:  time.Minute,
+		PasswordPolicy: DefaultPasswordPolicy,
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+	if err := options.PasswordPolicy.validate(); err != nil {
+		log.Fatalf("Invalid password policy: %v", err)
+	}
+	if options.Mailer == nil {
+		outbox, err := mailer.NewOutbox(filepath.Join(os.TempDir(), "blog-outbox"))
+		if err != nil {
+			log.Fatalf("Failed to create mail outbox: %v", err)
+		}
+		options.Mailer = outbox
+	}
+
+	h := &Handler{opts: options}
+	dummy, err := options.PasswordPolicy.hash("dummy-password")
+	if err != nil {
+		log.Fatalf("Failed to hash password: %v", err)
+	}
+	h.dummyHash = dummy
+	// Index users written before the email and handle indexes existed
+	h.reindex()
+	if options.UserDeleted != nil && options.DeletionRetry > 0 {
+		go h.retryDeletions()
+	}
+	return h
+}
+
+// normalizeEmail returns the canonical form of an email used for lookups
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// normalizeHandle returns the canonical form of a handle
+func normalizeHandle(handle string) string {
+	return strings.ToLower(strings.TrimSpace(handle))
+}
+
+// handleFromName derives a handle from a display name, e.g. "Jane Doe" -> "jane-doe"
+func handleFromName(name string) string {
+	h := invalidHandle.ReplaceAllString(normalizeHandle(name), "-")
+	h = strings.Trim(h, "-")
+	if len(h) > 32 {
+		h = strings.Trim(h[:32], "-")
+	}
+	return h
+}
+
+// emailKey is the store key of the email -> user id index entry
+func emailKey(email string) string {
+	return "email-" + normalizeEmail(email)
+}
+
+// handleKey is the store key of the handle -> user id index entry
+func handleKey(handle string) string {
+	return "handle-" + normalizeHandle(handle)
+}
+
+// indexOwner returns the user id an index entry points at, or "" if unset
+func indexOwner(key string) string {
+	rec, err := userStore.Read(key)
+	if err != nil || len(rec) == 0 {
+		return ""
+	}
+	return string(rec[0].Value)
+}
+
+// readUser loads a user record, returning nil if it doesn't exist
+func readUser(id string) *pb.User {
+	rec, err := userStore.Read("user-" + id)
+	if err != nil || len(rec) == 0 {
+		return nil
+	}
+	var user pb.User
+	if err := json.Unmarshal(rec[0].Value, &user); err != nil {
+		return nil
+	}
+	return &user
+}
+
+func (h *Handler) reindex() {
+	mu.Lock()
+	defer mu.Unlock()
+
+	rec, err := userStore.Read("user-", store.ReadPrefix())
+	if err != nil {
+		return
+	}
+	for _, r := range rec {
+		var u pb.User
+		if err := json.Unmarshal(r.Value, &u); err != nil {
+			continue
+		}
+		// Move hashes stored on the user record by older versions
+		var legacy struct {
+			Password string `json:"password"`
+		}
+		if err := json.Unmarshal(r.Value, &legacy); err == nil && legacy.Password != "" {
+			if readPasswordHash(u.Id) == "" {
+				_ = userStore.Write(&store.Record{Key: passwordKey(u.Id), Value: []byte(legacy.Password)})
+			}
+			if b, err := json.Marshal(&u); err == nil {
+				_ = userStore.Write(&store.Record{Key: "user-" + u.Id, Value: b})
+			}
+		}
+		if u.Email != "" && indexOwner(emailKey(u.Email)) == "" {
+			_ = userStore.Write(&store.Record{Key: emailKey(u.Email), Value: []byte(u.Id)})
+		}
+		if u.Handle == "" {
+			u.Handle = handleFromName(u.Name)
+			if u.Handle == "" || indexOwner(handleKey(u.Handle)) != "" {
+				u.Handle = strings.Trim(u.Handle+"-"+u.Id[:8], "-")
+			}
+			if b, err := json.Marshal(&u); err == nil {
+				_ = userStore.Write(&store.Record{Key: "user-" + u.Id, Value: b})
+			}
+		}
+		if indexOwner(handleKey(u.Handle)) == "" {
+			_ = userStore.Write(&store.Record{Key: handleKey(u.Handle), Value: []byte(u.Id)})
+		}
+		// Users from before roles existed get the default role
+		if len(u.Roles) == 0 {
+			u.Roles = []string{defaultRole}
+			if b, err := json.Marshal(&u); err == nil {
+				_ = userStore.Write(&store.Record{Key: "user-" + u.Id, Value: b})
+			}
+		}
+	}
+	if h.opts.AdminEmail != "" {
+		grantAdmin(h.opts.AdminEmail)
+	}
+	if len(rec) > 0 && countAdmins() == 0 {
+		log.Println("No user has the admin role; set ADMIN_EMAIL to grant it")
+	}
 }
 
 func (h *Handler) Create(ctx context.Context, req *pb.CreateRequest, rsp *pb.CreateResponse) error {
-	id := uuid.New().String()
+	email := normalizeEmail(req.Email)
+	if email == "" {
+		return errors.BadRequest("users.Create", "email required")
+	}
+	handle := normalizeHandle(req.Handle)
+	if handle != "" && !validHandle.MatchString(handle) {
+		return errors.BadRequest("users.Create", "handle must be 1-32 characters of a-z, 0-9, _ or -")
+	}
 
-	user := &pb.User{
-		Id:       id,
-		Name:     req.Name,
-		Email:    req.Email,
-		Password: req.Password, // store hash
+	var pwHash string
+	if req.Password != "" {
+		var err error
+		if pwHash, err = h.hashPassword(req.Password); err != nil {
+			return errors.InternalServerError("users.Create", "failed to hash password")
+		}
 	}
 
+	mu.Lock()
+	if indexOwner(emailKey(email)) != "" {
+		mu.Unlock()
+		return errors.Conflict("users.Create", "email already registered")
+	}
+	// Only a handle the user chose can be taken; one derived from the name
+	// gets a number added instead, e.g. "jane-doe-2"
+	if handle == "" {
+		handle = freeHandle(req.Name, strings.Split(email, "@")[0])
+	} else if indexOwner(handleKey(handle)) != "" {
+		mu.Unlock()
+		return errors.Conflict("users.Create", "handle already taken")
+	}
+	user, verification, err := h.createUser(req.Name, email, handle, pwHash, false)
+	mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	h.sendVerification(verification)
+	h.record(ctx, "user.create", "", user.Id, nil)
 	rsp.User = user
 
+	return nil
+}
+
+// createUser saves a new user and its indexes. The caller must hold mu and
+// have checked the email and handle are free. An unverified user also gets
+// a verification mail, for the caller to send after releasing mu.
+func (h *Handler) createUser(name, email, handle, pwHash string, verified bool) (*pb.User, *mailer.Message, error) {
+	// The first account administers the blog
+	roles := []string{defaultRole}
+	if !hasUsers() {
+		roles = []string{adminRole}
+	}
+
+	user := &pb.User{
+		Id:        uuid.New().String(),
+		Name:      name,
+		Email:     email,
+		Handle:    handle,
+		Verified:  verified,
+		Roles:     roles,
+		CreatedAt: time.Now().Unix(),
+	}
+
 	// Save to store
 	b, err := json.Marshal(user)
-	if err == nil {
-		_ = userStore.Write(&store.Record{Key: "user-" + user.Id, Value: b})
+	if err != nil {
+		return nil, nil, errors.InternalServerError("users.Create", "failed to encode user")
 	}
+	if err := userStore.Write(&store.Record{Key: "user-" + user.Id, Value: b}); err != nil {
+		return nil, nil, errors.InternalServerError("users.Create", "failed to save user")
+	}
+	if pwHash != "" {
+		_ = userStore.Write(&store.Record{Key: passwordKey(user.Id), Value: []byte(pwHash)})
+	}
+	_ = userStore.Write(&store.Record{Key: emailKey(email), Value: []byte(user.Id)})
+	_ = userStore.Write(&store.Record{Key: handleKey(handle), Value: []byte(user.Id)})
 
-	return nil
+	if verified {
+		return user, nil, nil
+	}
+	verification, err := h.verification(user)
+	if err != nil {
+		log.Printf("Failed to issue verification token for %s: %v", user.Email, err)
+	}
+	return user, verification, nil
 }
 
 func (h *Handler) Read(ctx context.Context, req *pb.ReadRequest, rsp *pb.ReadResponse) error {
@@ -52,7 +282,45 @@ func (h *Handler) Read(ctx context.Context, req *pb.ReadRequest, rsp *pb.ReadRes
 	return nil
 }
 
+func (h *Handler) ReadByEmail(ctx context.Context, req *pb.ReadByEmailRequest, rsp *pb.ReadByEmailResponse) error {
+	rsp.User = nil
+	if normalizeEmail(req.Email) == "" {
+		return nil
+	}
+	if id := indexOwner(emailKey(req.Email)); id != "" {
+		rsp.User = readUser(id)
+	}
+	return nil
+}
+
+func (h *Handler) VerifyCredentials(ctx context.Context, req *pb.VerifyCredentialsRequest, rsp *pb.VerifyCredentialsResponse) error {
+	var user *pb.User
+	if normalizeEmail(req.Email) != "" {
+		if id := indexOwner(emailKey(req.Email)); id != "" {
+			user = readUser(id)
+		}
+	}
+
+	// Always run a compare so unknown emails aren't revealed by timing
+	hash, ok := h.dummyHash, false
+	if user != nil {
+		if stored := readPasswordHash(user.Id); stored != "" {
+			hash, ok = stored, true
+		}
+	}
+	if !checkPasswordHash(req.Password, hash) || !ok {
+		return errors.Unauthorized("users.VerifyCredentials", "invalid credentials")
+	}
+	h.rehashPassword(user.Id, req.Password, hash)
+
+	rsp.User = user
+	return nil
+}
+
 func (h *Handler) Update(ctx context.Context, req *pb.UpdateRequest, rsp *pb.UpdateResponse) error {
+	mu.Lock()
+	defer mu.Unlock()
+
 	rec, err := userStore.Read("user-" + req.Id)
 	if err != nil || len(rec) == 0 {
 		rsp.User = nil
@@ -63,21 +331,96 @@ func (h *Handler) Update(ctx context.Context, req *pb.UpdateRequest, rsp *pb.Upd
 		rsp.User = nil
 		return nil
 	}
-	user.Name = req.Name
-	user.Email = req.Email
+
+	// The email only changes through RequestEmailChange, which checks the
+	// password and confirms the new address first
+	if email := normalizeEmail(req.Email); email != "" && email != normalizeEmail(user.Email) {
+		return errors.BadRequest("users.Update", "use RequestEmailChange to change the email address")
+	}
+	// An empty name keeps the current one, like the handle
+	if req.Name != "" && strings.TrimSpace(req.Name) == "" {
+		return errors.BadRequest("users.Update", "name can't be blank")
+	}
+	oldHandle := user.Handle
+	handle := normalizeHandle(req.Handle)
+	if handle == "" {
+		handle = oldHandle
+	}
+	if !validHandle.MatchString(handle) {
+		return errors.BadRequest("users.Update", "handle must be 1-32 characters of a-z, 0-9, _ or -")
+	}
+	if owner := indexOwner(handleKey(handle)); owner != "" && owner != user.Id {
+		return errors.Conflict("users.Update", "handle already taken")
+	}
+
+	if err := applyProfile(&user, req); err != nil {
+		return err
+	}
+	if req.Name != "" {
+		user.Name = req.Name
+	}
+	user.Handle = handle
 	b, err := json.Marshal(&user)
-	if err == nil {
-		_ = userStore.Write(&store.Record{Key: "user-" + user.Id, Value: b})
+	if err != nil {
+		return errors.InternalServerError("users.Update", "failed to encode user")
 	}
+	if err := userStore.Write(&store.Record{Key: "user-" + user.Id, Value: b}); err != nil {
+		return errors.InternalServerError("users.Update", "failed to save user")
+	}
+	// Keep the handle index in step with the record
+	if normalizeHandle(oldHandle) != handle {
+		if oldHandle != "" {
+			_ = userStore.Delete(handleKey(oldHandle))
+		}
+		_ = userStore.Write(&store.Record{Key: handleKey(handle), Value: []byte(user.Id)})
+	}
+	h.record(ctx, "user.update", "", user.Id, nil)
 	rsp.User = &user
 	return nil
 }
 
 func (h *Handler) Delete(ctx context.Context, req *pb.DeleteRequest, rsp *pb.DeleteResponse) error {
-	_ = userStore.Delete("user-" + req.Id)
+	mu.Lock()
+	defer mu.Unlock()
+
+	deleteUser(req.Id)
+	h.record(ctx, "user.delete", "", req.Id, nil)
 	return nil
 }
 
+// deleteUser removes a user with their indexes, credentials, tokens,
+// identities, follows, blocks and mutes. The caller must hold mu.
+func deleteUser(id string) {
+	rec, err := userStore.Read("user-" + id)
+	if err == nil && len(rec) > 0 {
+		var user pb.User
+		if err := json.Unmarshal(rec[0].Value, &user); err == nil {
+			if user.Email != "" {
+				_ = userStore.Delete(emailKey(user.Email))
+			}
+			if user.Handle != "" {
+				_ = userStore.Delete(handleKey(user.Handle))
+			}
+		}
+	}
+	_ = userStore.Delete(passwordKey(id))
+	if hash := indexOwner(resetUserKey(id)); hash != "" {
+		_ = userStore.Delete(resetKey(hash))
+		_ = userStore.Delete(resetUserKey(id))
+	}
+	if hash := indexOwner(verifyUserKey(id)); hash != "" {
+		_ = userStore.Delete(verifyKey(hash))
+		_ = userStore.Delete(verifyUserKey(id))
+	}
+	cancelEmailChange(id)
+	revokeTokens(id)
+	_ = userStore.Delete(totpKey(id))
+	unlinkIdentities(id)
+	unfollowAll(id)
+	unblockAll(id)
+	_ = userStore.Delete("user-" + id)
+}
+
 func (h *Handler) List(ctx context.Context, req *pb.ListRequest, rsp *pb.ListResponse) error {
 	rec, err := userStore.Read("user-", store.ReadPrefix())
 	if err == nil && len(rec) > 0 {
@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	pb "github.com/micro/blog/users/proto"
+	"go-micro.dev/v5/errors"
+)
+
+func TestUpdateKeepsEmail(t *testing.T) {
+	h, _ := newTestHandler(t)
+	user := createUser(t, h, "Jane Doe", "jane@example.com", "secret-password")
+
+	// A new address has to go through RequestEmailChange
+	err := h.Update(context.Background(), &pb.UpdateRequest{Id: user.Id, Name: "Jane", Email: "mallory@example.com"}, &pb.UpdateResponse{})
+	if errors.FromError(err).Code != http.StatusBadRequest {
+		t.Fatalf("Update with a new email = %v, want 400", err)
+	}
+	if indexOwner(emailKey("mallory@example.com")) != "" {
+		t.Error("Update indexed the new email")
+	}
+
+	// The current address, or none, leaves it alone
+	for _, email := range []string{"", "Jane@Example.com"} {
+		var rsp pb.UpdateResponse
+		if err := h.Update(context.Background(), &pb.UpdateRequest{Id: user.Id, Name: "Jane", Email: email}, &rsp); err != nil {
+			t.Fatalf("Update with email %q: %v", email, err)
+		}
+		if rsp.User.Email != "jane@example.com" || rsp.User.Name != "Jane" {
+			t.Errorf("Update with email %q returned %+v", email, rsp.User)
+		}
+	}
+}
+
+func TestUpdateName(t *testing.T) {
+	h, _ := newTestHandler(t)
+	user := createUser(t, h, "Jane Doe", "jane@example.com", "secret-password")
+
+	// Leaving the name out keeps it
+	bio := "Hello"
+	var rsp pb.UpdateResponse
+	if err := h.Update(context.Background(), &pb.UpdateRequest{Id: user.Id, Bio: &bio}, &rsp); err != nil {
+		t.Fatalf("Update without a name: %v", err)
+	}
+	if rsp.User.Name != "Jane Doe" || rsp.User.Bio != "Hello" {
+		t.Errorf("Update without a name returned %+v", rsp.User)
+	}
+
+	err := h.Update(context.Background(), &pb.UpdateRequest{Id: user.Id, Name: "  "}, &pb.UpdateResponse{})
+	if errors.FromError(err).Code != http.StatusBadRequest {
+		t.Fatalf("Update with a blank name = %v, want 400", err)
+	}
+	if u := readUser(user.Id); u == nil || u.Name != "Jane Doe" {
+		t.Errorf("user after a rejected update: %+v", u)
+	}
+
+	if err := h.Update(context.Background(), &pb.UpdateRequest{Id: user.Id, Name: "Jane"}, &rsp); err != nil || rsp.User.Name != "Jane" {
+		t.Errorf("Update with a new name = %+v (%v)", rsp.User, err)
+	}
+}
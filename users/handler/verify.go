@@ -0,0 +1,137 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/micro/blog/users/mailer"
+	pb "github.com/micro/blog/users/proto"
+	"go-micro.dev/v5/errors"
+	"go-micro.dev/v5/store"
+)
+
+// verifyToken is the stored state of an outstanding email verification
+type verifyToken struct {
+	UserId    string `json:"user_id"`
+	Email     string `json:"email"`
+	ExpiresAt int64  `json:"expires_at"`
+}
+
+// verifyKey is the store key of a verification token, addressed by its hash
+func verifyKey(hash string) string {
+	return "verify-" + hash
+}
+
+// verifyUserKey points at the latest verification token issued to a user
+func verifyUserKey(id string) string {
+	return "verifyuser-" + id
+}
+
+// verification issues a token for the user's current email and returns
+// the mail with a link to it. Callers must hold mu, and send the mail once
+// they've released it so a slow mail server doesn't hold up other requests.
+func (h *Handler) verification(user *pb.User) (*mailer.Message, error) {
+	token, err := newToken()
+	if err != nil {
+		return nil, err
+	}
+	hash := hashToken(token)
+	b, _ := json.Marshal(&verifyToken{
+		UserId:    user.Id,
+		Email:     user.Email,
+		ExpiresAt: time.Now().Add(h.opts.VerifyTokenTTL).Unix(),
+	})
+
+	if prev := indexOwner(verifyUserKey(user.Id)); prev != "" {
+		_ = userStore.Delete(verifyKey(prev))
+	}
+	if err := userStore.Write(&store.Record{Key: verifyKey(hash), Value: b, Expiry: h.opts.VerifyTokenTTL}); err != nil {
+		return nil, err
+	}
+	_ = userStore.Write(&store.Record{Key: verifyUserKey(user.Id), Value: []byte(hash), Expiry: h.opts.VerifyTokenTTL})
+
+	return &mailer.Message{
+		To:      user.Email,
+		Subject: "Confirm your email address",
+		Body: fmt.Sprintf("Hi %s,\n\nPlease confirm your email address by following this link:\n\n"+
+			"%s/verify?token=%s\n\nThe link expires in %s.\n",
+			user.Name, h.opts.BaseURL, token, h.opts.VerifyTokenTTL),
+	}, nil
+}
+
+// sendVerification sends a mail from verification, if there is one,
+// logging failures
+func (h *Handler) sendVerification(msg *mailer.Message) {
+	if msg == nil {
+		return
+	}
+	if err := h.opts.Mailer.Send(msg); err != nil {
+		log.Printf("Failed to send verification email to %s: %v", msg.To, err)
+	}
+}
+
+func (h *Handler) VerifyEmail(ctx context.Context, req *pb.VerifyEmailRequest, rsp *pb.VerifyEmailResponse) error {
+	if req.Token == "" {
+		return errors.BadRequest("users.VerifyEmail", "invalid or expired token")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	hash := hashToken(req.Token)
+	rec, err := userStore.Read(verifyKey(hash))
+	if err != nil || len(rec) == 0 {
+		return errors.BadRequest("users.VerifyEmail", "invalid or expired token")
+	}
+	_ = userStore.Delete(verifyKey(hash))
+
+	var tok verifyToken
+	if err := json.Unmarshal(rec[0].Value, &tok); err != nil || time.Now().Unix() > tok.ExpiresAt {
+		return errors.BadRequest("users.VerifyEmail", "invalid or expired token")
+	}
+	_ = userStore.Delete(verifyUserKey(tok.UserId))
+
+	// The token only confirms the address it was sent to
+	user := readUser(tok.UserId)
+	if user == nil || normalizeEmail(user.Email) != normalizeEmail(tok.Email) {
+		return errors.BadRequest("users.VerifyEmail", "invalid or expired token")
+	}
+	user.Verified = true
+	b, err := json.Marshal(user)
+	if err != nil {
+		return errors.InternalServerError("users.VerifyEmail", "failed to encode user")
+	}
+	if err := userStore.Write(&store.Record{Key: "user-" + user.Id, Value: b}); err != nil {
+		return errors.InternalServerError("users.VerifyEmail", "failed to save user")
+	}
+
+	rsp.User = user
+	return nil
+}
+
+func (h *Handler) ResendVerification(ctx context.Context, req *pb.ResendVerificationRequest, rsp *pb.ResendVerificationResponse) error {
+	mu.Lock()
+	user := readUser(req.UserId)
+	if user == nil {
+		mu.Unlock()
+		return errors.NotFound("users.ResendVerification", "user not found")
+	}
+	if user.Verified {
+		mu.Unlock()
+		return errors.BadRequest("users.ResendVerification", "email already verified")
+	}
+	msg, err := h.verification(user)
+	mu.Unlock()
+	if err != nil {
+		return errors.InternalServerError("users.ResendVerification", "failed to save token")
+	}
+
+	if err := h.opts.Mailer.Send(msg); err != nil {
+		log.Printf("Failed to send verification email to %s: %v", user.Email, err)
+		return errors.InternalServerError("users.ResendVerification", "failed to send email")
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/micro/blog/users/mailer"
+	pb "github.com/micro/blog/users/proto"
+)
+
+func TestVerifyEmail(t *testing.T) {
+	h, outbox := newTestHandler(t)
+	user := createUser(t, h, "Jane Doe", "jane@example.com", "secret-password")
+	if user.Verified {
+		t.Fatal("new user is verified before confirming their email")
+	}
+	token := lastToken(t, outbox, "jane@example.com")
+
+	var rsp pb.VerifyEmailResponse
+	if err := h.VerifyEmail(context.Background(), &pb.VerifyEmailRequest{Token: token}, &rsp); err != nil {
+		t.Fatalf("VerifyEmail: %v", err)
+	}
+	if !rsp.User.Verified {
+		t.Error("VerifyEmail returned an unverified user")
+	}
+	if u := readUser(user.Id); u == nil || !u.Verified {
+		t.Error("user is not stored as verified")
+	}
+
+	// Tokens are single use
+	if err := h.VerifyEmail(context.Background(), &pb.VerifyEmailRequest{Token: token}, &pb.VerifyEmailResponse{}); err == nil {
+		t.Error("VerifyEmail accepted a used token")
+	}
+}
+
+func TestVerifyEmailExpired(t *testing.T) {
+	h, outbox := newTestHandler(t, WithVerifyTokenTTL(50*time.Millisecond))
+	user := createUser(t, h, "Jane Doe", "jane@example.com", "secret-password")
+	token := lastToken(t, outbox, "jane@example.com")
+
+	time.Sleep(100 * time.Millisecond)
+	if err := h.VerifyEmail(context.Background(), &pb.VerifyEmailRequest{Token: token}, &pb.VerifyEmailResponse{}); err == nil {
+		t.Error("VerifyEmail accepted an expired token")
+	}
+	if u := readUser(user.Id); u == nil || u.Verified {
+		t.Error("expired token verified the user")
+	}
+}
+
+func TestVerifyEmailLatestToken(t *testing.T) {
+	h, outbox := newTestHandler(t)
+	user := createUser(t, h, "Jane Doe", "jane@example.com", "secret-password")
+	first := lastToken(t, outbox, "jane@example.com")
+
+	if err := h.ResendVerification(context.Background(), &pb.ResendVerificationRequest{UserId: user.Id}, &pb.ResendVerificationResponse{}); err != nil {
+		t.Fatalf("ResendVerification: %v", err)
+	}
+	if err := h.VerifyEmail(context.Background(), &pb.VerifyEmailRequest{Token: first}, &pb.VerifyEmailResponse{}); err == nil {
+		t.Error("VerifyEmail accepted a token that was replaced")
+	}
+	second := lastToken(t, outbox, "jane@example.com")
+	if err := h.VerifyEmail(context.Background(), &pb.VerifyEmailRequest{Token: second}, &pb.VerifyEmailResponse{}); err != nil {
+		t.Errorf("VerifyEmail with the latest token: %v", err)
+	}
+}
+
+// lockCheckMailer records whether mu was held while sending
+type lockCheckMailer struct {
+	sent, underLock int
+}
+
+func (m *lockCheckMailer) Send(*mailer.Message) error {
+	m.sent++
+	if mu.TryLock() {
+		mu.Unlock()
+	} else {
+		m.underLock++
+	}
+	return nil
+}
+
+func TestVerificationSentWithoutLock(t *testing.T) {
+	m := &lockCheckMailer{}
+	h, _ := newTestHandler(t, WithMailer(m))
+	user := createUser(t, h, "Jane Doe", "jane@example.com", "secret-password")
+	if err := h.ResendVerification(context.Background(), &pb.ResendVerificationRequest{UserId: user.Id}, &pb.ResendVerificationResponse{}); err != nil {
+		t.Fatalf("ResendVerification: %v", err)
+	}
+	req := &pb.ResolveIdentityRequest{Issuer: "https://id.example.com", Subject: "john", Email: "john@example.com"}
+	if err := h.ResolveIdentity(context.Background(), req, &pb.ResolveIdentityResponse{}); err != nil {
+		t.Fatalf("ResolveIdentity: %v", err)
+	}
+
+	// A slow mail server mustn't hold up every other signup and update
+	if m.sent != 3 || m.underLock != 0 {
+		t.Errorf("sent %d verification mails, %d of them holding mu; want 3 and none", m.sent, m.underLock)
+	}
+}
@@ -0,0 +1,14 @@
+// Package mailer sends transactional email such as password reset links.
+package mailer
+
+// Message is a plain text email to a single recipient
+type Message struct {
+	To      string `json:"to"`
+	Subject string `json:"subject"`
+	Body    string `json:"body"`
+}
+
+// Mailer delivers messages
+type Mailer interface {
+	Send(msg *Message) error
+}
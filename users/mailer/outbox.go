@@ -0,0 +1,79 @@
+package mailer
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Outbox writes each message as a JSON file in a directory instead of
+// delivering it. It's used in development and tests to inspect mail.
+type Outbox struct {
+	Dir string
+
+	mu  sync.Mutex
+	seq int
+}
+
+// NewOutbox returns an Outbox writing to dir, creating it if needed
+func NewOutbox(dir string) (*Outbox, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	return &Outbox{Dir: dir}, nil
+}
+
+func (o *Outbox) Send(msg *Message) error {
+	b, err := json.MarshalIndent(msg, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	o.mu.Lock()
+	o.seq++
+	name := fmt.Sprintf("%d-%04d.json", time.Now().UnixNano(), o.seq)
+	o.mu.Unlock()
+
+	return os.WriteFile(filepath.Join(o.Dir, name), b, 0600)
+}
+
+// Messages returns every message in the outbox, oldest first
+func (o *Outbox) Messages() ([]*Message, error) {
+	files, err := filepath.Glob(filepath.Join(o.Dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+
+	var msgs []*Message
+	for _, f := range files {
+		b, err := os.ReadFile(f)
+		if err != nil {
+			return nil, err
+		}
+		var m Message
+		if err := json.Unmarshal(b, &m); err != nil {
+			return nil, err
+		}
+		msgs = append(msgs, &m)
+	}
+	return msgs, nil
+}
+
+// Last returns the most recent message sent to an address, or nil
+func (o *Outbox) Last(to string) (*Message, error) {
+	msgs, err := o.Messages()
+	if err != nil {
+		return nil, err
+	}
+	for i := len(msgs) - 1; i >= 0; i-- {
+		if msgs[i].To == to {
+			return msgs[i], nil
+		}
+	}
+	return nil, nil
+}
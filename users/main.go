@@ -1,16 +1,113 @@
 package main
 
 import (
+	"log"
+	"os"
+	"strconv"
+	"time"
+
 	"go-micro.dev/v5"
+	"go-micro.dev/v5/server"
 
+	"github.com/micro/blog/audit/recorder"
 	"github.com/micro/blog/users/handler"
+	"github.com/micro/blog/users/mailer"
 	pb "github.com/micro/blog/users/proto"
 )
 
+// getenv returns the value of an environment variable or a default
+func getenv(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
+
+// newMailer sends through SMTP_HOST when set, otherwise to a local outbox
+// directory (MAIL_OUTBOX_DIR) that can be inspected during development.
+func newMailer() mailer.Mailer {
+	if host := os.Getenv("SMTP_HOST"); host != "" {
+		return mailer.NewSMTP(
+			host,
+			getenv("SMTP_PORT", "587"),
+			os.Getenv("SMTP_USERNAME"),
+			os.Getenv("SMTP_PASSWORD"),
+			getenv("SMTP_FROM", "blog@localhost"),
+		)
+	}
+	dir := getenv("MAIL_OUTBOX_DIR", "outbox")
+	outbox, err := mailer.NewOutbox(dir)
+	if err != nil {
+		log.Fatalf("Failed to create mail outbox %s: %v", dir, err)
+	}
+	log.Printf("SMTP_HOST not set, writing email to %s", dir)
+	return outbox
+}
+
+// passwordPolicy reads how passwords are hashed: PASSWORD_HASH is bcrypt
+// (the default) or argon2id, tuned by BCRYPT_COST or ARGON2_TIME,
+// ARGON2_MEMORY (KiB) and ARGON2_THREADS
+func passwordPolicy() handler.PasswordPolicy {
+	p := handler.DefaultPasswordPolicy
+	p.Algorithm = getenv("PASSWORD_HASH", p.Algorithm)
+	uintEnv := func(key string, bits int, def uint64) uint64 {
+		v := os.Getenv(key)
+		if v == "" {
+			return def
+		}
+		n, err := strconv.ParseUint(v, 10, bits)
+		if err != nil {
+			log.Fatalf("Invalid %s %q: %v", key, v, err)
+		}
+		return n
+	}
+	p.BcryptCost = int(uintEnv("BCRYPT_COST", 8, uint64(p.BcryptCost)))
+	p.Argon2Time = uint32(uintEnv("ARGON2_TIME", 32, uint64(p.Argon2Time)))
+	p.Argon2Memory = uint32(uintEnv("ARGON2_MEMORY", 32, uint64(p.Argon2Memory)))
+	p.Argon2Threads = uint8(uintEnv("ARGON2_THREADS", 8, uint64(p.Argon2Threads)))
+	return p
+}
+
 func main() {
 	service := micro.New("users")
 
-	pb.RegisterUsersHandler(service.Server(), handler.New())
+	opts := []handler.Option{
+		handler.WithUserDeleted(micro.NewEvent("user.deleted", service.Client())),
+		handler.WithAudit(recorder.New("users", service.Client())),
+		handler.WithMailer(newMailer()),
+		handler.WithBaseURL(getenv("BLOG_URL", "http://localhost:42096")),
+		handler.WithAdminEmail(os.Getenv("ADMIN_EMAIL")),
+		handler.WithTOTPIssuer(getenv("TOTP_ISSUER", "Micro Blog")),
+		handler.WithPasswordPolicy(passwordPolicy()),
+	}
+	if v := os.Getenv("RESET_TOKEN_TTL"); v != "" {
+		ttl, err := time.ParseDuration(v)
+		if err != nil {
+			log.Fatalf("Invalid RESET_TOKEN_TTL %q: %v", v, err)
+		}
+		opts = append(opts, handler.WithResetTokenTTL(ttl))
+	}
+	if v := os.Getenv("VERIFY_TOKEN_TTL"); v != "" {
+		ttl, err := time.ParseDuration(v)
+		if err != nil {
+			log.Fatalf("Invalid VERIFY_TOKEN_TTL %q: %v", v, err)
+		}
+		opts = append(opts, handler.WithVerifyTokenTTL(ttl))
+	}
+	if v := os.Getenv("DELETION_RETRY"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			log.Fatalf("Invalid DELETION_RETRY %q: %v", v, err)
+		}
+		opts = append(opts, handler.WithDeletionRetry(d))
+	}
+
+	h := handler.New(opts...)
+	pb.RegisterUsersHandler(service.Server(), h)
+	// Content services report on account deletions here
+	if err := micro.RegisterSubscriber("user.deletion.progress", service.Server(), h.DeletionProgress, server.SubscriberQueue("users")); err != nil {
+		log.Fatalf("Failed to subscribe to deletion progress: %v", err)
+	}
 
 	service.Init()
 
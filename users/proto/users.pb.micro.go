@@ -33,6 +33,51 @@ type UsersService interface {
 	Update(ctx context.Context, in *UpdateRequest, opts ...client.CallOption) (*UpdateResponse, error)
 	Delete(ctx context.Context, in *DeleteRequest, opts ...client.CallOption) (*DeleteResponse, error)
 	List(ctx context.Context, in *ListRequest, opts ...client.CallOption) (*ListResponse, error)
+	ReadByEmail(ctx context.Context, in *ReadByEmailRequest, opts ...client.CallOption) (*ReadByEmailResponse, error)
+	ReadByHandle(ctx context.Context, in *ReadByHandleRequest, opts ...client.CallOption) (*ReadByHandleResponse, error)
+	VerifyCredentials(ctx context.Context, in *VerifyCredentialsRequest, opts ...client.CallOption) (*VerifyCredentialsResponse, error)
+	// == Password reset ==
+	RequestPasswordReset(ctx context.Context, in *RequestPasswordResetRequest, opts ...client.CallOption) (*RequestPasswordResetResponse, error)
+	ResetPassword(ctx context.Context, in *ResetPasswordRequest, opts ...client.CallOption) (*ResetPasswordResponse, error)
+	// == Email verification ==
+	VerifyEmail(ctx context.Context, in *VerifyEmailRequest, opts ...client.CallOption) (*VerifyEmailResponse, error)
+	ResendVerification(ctx context.Context, in *ResendVerificationRequest, opts ...client.CallOption) (*ResendVerificationResponse, error)
+	// == Account changes ==
+	ChangePassword(ctx context.Context, in *ChangePasswordRequest, opts ...client.CallOption) (*ChangePasswordResponse, error)
+	RequestEmailChange(ctx context.Context, in *RequestEmailChangeRequest, opts ...client.CallOption) (*RequestEmailChangeResponse, error)
+	ConfirmEmailChange(ctx context.Context, in *ConfirmEmailChangeRequest, opts ...client.CallOption) (*ConfirmEmailChangeResponse, error)
+	// == Personal access tokens ==
+	CreateToken(ctx context.Context, in *CreateTokenRequest, opts ...client.CallOption) (*CreateTokenResponse, error)
+	ListTokens(ctx context.Context, in *ListTokensRequest, opts ...client.CallOption) (*ListTokensResponse, error)
+	RevokeToken(ctx context.Context, in *RevokeTokenRequest, opts ...client.CallOption) (*RevokeTokenResponse, error)
+	VerifyToken(ctx context.Context, in *VerifyTokenRequest, opts ...client.CallOption) (*VerifyTokenResponse, error)
+	// == Roles ==
+	SetRoles(ctx context.Context, in *SetRolesRequest, opts ...client.CallOption) (*SetRolesResponse, error)
+	// == Two-factor authentication ==
+	EnrollTOTP(ctx context.Context, in *EnrollTOTPRequest, opts ...client.CallOption) (*EnrollTOTPResponse, error)
+	ConfirmTOTP(ctx context.Context, in *ConfirmTOTPRequest, opts ...client.CallOption) (*ConfirmTOTPResponse, error)
+	DisableTOTP(ctx context.Context, in *DisableTOTPRequest, opts ...client.CallOption) (*DisableTOTPResponse, error)
+	VerifyTOTP(ctx context.Context, in *VerifyTOTPRequest, opts ...client.CallOption) (*VerifyTOTPResponse, error)
+	// == External identities ==
+	ResolveIdentity(ctx context.Context, in *ResolveIdentityRequest, opts ...client.CallOption) (*ResolveIdentityResponse, error)
+	ListIdentities(ctx context.Context, in *ListIdentitiesRequest, opts ...client.CallOption) (*ListIdentitiesResponse, error)
+	// == Account deletion ==
+	DeleteAccount(ctx context.Context, in *DeleteAccountRequest, opts ...client.CallOption) (*DeleteAccountResponse, error)
+	ReadDeletion(ctx context.Context, in *ReadDeletionRequest, opts ...client.CallOption) (*ReadDeletionResponse, error)
+	// == Follows ==
+	Follow(ctx context.Context, in *FollowRequest, opts ...client.CallOption) (*FollowResponse, error)
+	Unfollow(ctx context.Context, in *UnfollowRequest, opts ...client.CallOption) (*UnfollowResponse, error)
+	FollowTag(ctx context.Context, in *FollowTagRequest, opts ...client.CallOption) (*FollowTagResponse, error)
+	UnfollowTag(ctx context.Context, in *UnfollowTagRequest, opts ...client.CallOption) (*UnfollowTagResponse, error)
+	ListFollowing(ctx context.Context, in *ListFollowingRequest, opts ...client.CallOption) (*ListFollowingResponse, error)
+	ListFollowers(ctx context.Context, in *ListFollowersRequest, opts ...client.CallOption) (*ListFollowersResponse, error)
+	// == Blocks and mutes ==
+	Block(ctx context.Context, in *BlockRequest, opts ...client.CallOption) (*BlockResponse, error)
+	Unblock(ctx context.Context, in *UnblockRequest, opts ...client.CallOption) (*UnblockResponse, error)
+	Mute(ctx context.Context, in *MuteRequest, opts ...client.CallOption) (*MuteResponse, error)
+	Unmute(ctx context.Context, in *UnmuteRequest, opts ...client.CallOption) (*UnmuteResponse, error)
+	ListBlocks(ctx context.Context, in *ListBlocksRequest, opts ...client.CallOption) (*ListBlocksResponse, error)
+	IsBlocked(ctx context.Context, in *IsBlockedRequest, opts ...client.CallOption) (*IsBlockedResponse, error)
 }
 
 type usersService struct {
@@ -97,6 +142,356 @@ func (c *usersService) List(ctx context.Context, in *ListRequest, opts ...client
 	return out, nil
 }
 
+func (c *usersService) ReadByEmail(ctx context.Context, in *ReadByEmailRequest, opts ...client.CallOption) (*ReadByEmailResponse, error) {
+	req := c.c.NewRequest(c.name, "Users.ReadByEmail", in)
+	out := new(ReadByEmailResponse)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *usersService) ReadByHandle(ctx context.Context, in *ReadByHandleRequest, opts ...client.CallOption) (*ReadByHandleResponse, error) {
+	req := c.c.NewRequest(c.name, "Users.ReadByHandle", in)
+	out := new(ReadByHandleResponse)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *usersService) VerifyCredentials(ctx context.Context, in *VerifyCredentialsRequest, opts ...client.CallOption) (*VerifyCredentialsResponse, error) {
+	req := c.c.NewRequest(c.name, "Users.VerifyCredentials", in)
+	out := new(VerifyCredentialsResponse)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *usersService) RequestPasswordReset(ctx context.Context, in *RequestPasswordResetRequest, opts ...client.CallOption) (*RequestPasswordResetResponse, error) {
+	req := c.c.NewRequest(c.name, "Users.RequestPasswordReset", in)
+	out := new(RequestPasswordResetResponse)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *usersService) ResetPassword(ctx context.Context, in *ResetPasswordRequest, opts ...client.CallOption) (*ResetPasswordResponse, error) {
+	req := c.c.NewRequest(c.name, "Users.ResetPassword", in)
+	out := new(ResetPasswordResponse)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *usersService) VerifyEmail(ctx context.Context, in *VerifyEmailRequest, opts ...client.CallOption) (*VerifyEmailResponse, error) {
+	req := c.c.NewRequest(c.name, "Users.VerifyEmail", in)
+	out := new(VerifyEmailResponse)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *usersService) ResendVerification(ctx context.Context, in *ResendVerificationRequest, opts ...client.CallOption) (*ResendVerificationResponse, error) {
+	req := c.c.NewRequest(c.name, "Users.ResendVerification", in)
+	out := new(ResendVerificationResponse)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *usersService) ChangePassword(ctx context.Context, in *ChangePasswordRequest, opts ...client.CallOption) (*ChangePasswordResponse, error) {
+	req := c.c.NewRequest(c.name, "Users.ChangePassword", in)
+	out := new(ChangePasswordResponse)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *usersService) RequestEmailChange(ctx context.Context, in *RequestEmailChangeRequest, opts ...client.CallOption) (*RequestEmailChangeResponse, error) {
+	req := c.c.NewRequest(c.name, "Users.RequestEmailChange", in)
+	out := new(RequestEmailChangeResponse)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *usersService) ConfirmEmailChange(ctx context.Context, in *ConfirmEmailChangeRequest, opts ...client.CallOption) (*ConfirmEmailChangeResponse, error) {
+	req := c.c.NewRequest(c.name, "Users.ConfirmEmailChange", in)
+	out := new(ConfirmEmailChangeResponse)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *usersService) CreateToken(ctx context.Context, in *CreateTokenRequest, opts ...client.CallOption) (*CreateTokenResponse, error) {
+	req := c.c.NewRequest(c.name, "Users.CreateToken", in)
+	out := new(CreateTokenResponse)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *usersService) ListTokens(ctx context.Context, in *ListTokensRequest, opts ...client.CallOption) (*ListTokensResponse, error) {
+	req := c.c.NewRequest(c.name, "Users.ListTokens", in)
+	out := new(ListTokensResponse)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *usersService) RevokeToken(ctx context.Context, in *RevokeTokenRequest, opts ...client.CallOption) (*RevokeTokenResponse, error) {
+	req := c.c.NewRequest(c.name, "Users.RevokeToken", in)
+	out := new(RevokeTokenResponse)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *usersService) VerifyToken(ctx context.Context, in *VerifyTokenRequest, opts ...client.CallOption) (*VerifyTokenResponse, error) {
+	req := c.c.NewRequest(c.name, "Users.VerifyToken", in)
+	out := new(VerifyTokenResponse)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *usersService) SetRoles(ctx context.Context, in *SetRolesRequest, opts ...client.CallOption) (*SetRolesResponse, error) {
+	req := c.c.NewRequest(c.name, "Users.SetRoles", in)
+	out := new(SetRolesResponse)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *usersService) EnrollTOTP(ctx context.Context, in *EnrollTOTPRequest, opts ...client.CallOption) (*EnrollTOTPResponse, error) {
+	req := c.c.NewRequest(c.name, "Users.EnrollTOTP", in)
+	out := new(EnrollTOTPResponse)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *usersService) ConfirmTOTP(ctx context.Context, in *ConfirmTOTPRequest, opts ...client.CallOption) (*ConfirmTOTPResponse, error) {
+	req := c.c.NewRequest(c.name, "Users.ConfirmTOTP", in)
+	out := new(ConfirmTOTPResponse)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *usersService) DisableTOTP(ctx context.Context, in *DisableTOTPRequest, opts ...client.CallOption) (*DisableTOTPResponse, error) {
+	req := c.c.NewRequest(c.name, "Users.DisableTOTP", in)
+	out := new(DisableTOTPResponse)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *usersService) VerifyTOTP(ctx context.Context, in *VerifyTOTPRequest, opts ...client.CallOption) (*VerifyTOTPResponse, error) {
+	req := c.c.NewRequest(c.name, "Users.VerifyTOTP", in)
+	out := new(VerifyTOTPResponse)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *usersService) ResolveIdentity(ctx context.Context, in *ResolveIdentityRequest, opts ...client.CallOption) (*ResolveIdentityResponse, error) {
+	req := c.c.NewRequest(c.name, "Users.ResolveIdentity", in)
+	out := new(ResolveIdentityResponse)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *usersService) ListIdentities(ctx context.Context, in *ListIdentitiesRequest, opts ...client.CallOption) (*ListIdentitiesResponse, error) {
+	req := c.c.NewRequest(c.name, "Users.ListIdentities", in)
+	out := new(ListIdentitiesResponse)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *usersService) DeleteAccount(ctx context.Context, in *DeleteAccountRequest, opts ...client.CallOption) (*DeleteAccountResponse, error) {
+	req := c.c.NewRequest(c.name, "Users.DeleteAccount", in)
+	out := new(DeleteAccountResponse)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *usersService) ReadDeletion(ctx context.Context, in *ReadDeletionRequest, opts ...client.CallOption) (*ReadDeletionResponse, error) {
+	req := c.c.NewRequest(c.name, "Users.ReadDeletion", in)
+	out := new(ReadDeletionResponse)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *usersService) Follow(ctx context.Context, in *FollowRequest, opts ...client.CallOption) (*FollowResponse, error) {
+	req := c.c.NewRequest(c.name, "Users.Follow", in)
+	out := new(FollowResponse)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *usersService) Unfollow(ctx context.Context, in *UnfollowRequest, opts ...client.CallOption) (*UnfollowResponse, error) {
+	req := c.c.NewRequest(c.name, "Users.Unfollow", in)
+	out := new(UnfollowResponse)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *usersService) FollowTag(ctx context.Context, in *FollowTagRequest, opts ...client.CallOption) (*FollowTagResponse, error) {
+	req := c.c.NewRequest(c.name, "Users.FollowTag", in)
+	out := new(FollowTagResponse)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *usersService) UnfollowTag(ctx context.Context, in *UnfollowTagRequest, opts ...client.CallOption) (*UnfollowTagResponse, error) {
+	req := c.c.NewRequest(c.name, "Users.UnfollowTag", in)
+	out := new(UnfollowTagResponse)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *usersService) ListFollowing(ctx context.Context, in *ListFollowingRequest, opts ...client.CallOption) (*ListFollowingResponse, error) {
+	req := c.c.NewRequest(c.name, "Users.ListFollowing", in)
+	out := new(ListFollowingResponse)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *usersService) ListFollowers(ctx context.Context, in *ListFollowersRequest, opts ...client.CallOption) (*ListFollowersResponse, error) {
+	req := c.c.NewRequest(c.name, "Users.ListFollowers", in)
+	out := new(ListFollowersResponse)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *usersService) Block(ctx context.Context, in *BlockRequest, opts ...client.CallOption) (*BlockResponse, error) {
+	req := c.c.NewRequest(c.name, "Users.Block", in)
+	out := new(BlockResponse)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *usersService) Unblock(ctx context.Context, in *UnblockRequest, opts ...client.CallOption) (*UnblockResponse, error) {
+	req := c.c.NewRequest(c.name, "Users.Unblock", in)
+	out := new(UnblockResponse)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *usersService) Mute(ctx context.Context, in *MuteRequest, opts ...client.CallOption) (*MuteResponse, error) {
+	req := c.c.NewRequest(c.name, "Users.Mute", in)
+	out := new(MuteResponse)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *usersService) Unmute(ctx context.Context, in *UnmuteRequest, opts ...client.CallOption) (*UnmuteResponse, error) {
+	req := c.c.NewRequest(c.name, "Users.Unmute", in)
+	out := new(UnmuteResponse)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *usersService) ListBlocks(ctx context.Context, in *ListBlocksRequest, opts ...client.CallOption) (*ListBlocksResponse, error) {
+	req := c.c.NewRequest(c.name, "Users.ListBlocks", in)
+	out := new(ListBlocksResponse)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *usersService) IsBlocked(ctx context.Context, in *IsBlockedRequest, opts ...client.CallOption) (*IsBlockedResponse, error) {
+	req := c.c.NewRequest(c.name, "Users.IsBlocked", in)
+	out := new(IsBlockedResponse)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // Server API for Users service
 
 type UsersHandler interface {
@@ -105,6 +500,51 @@ type UsersHandler interface {
 	Update(context.Context, *UpdateRequest, *UpdateResponse) error
 	Delete(context.Context, *DeleteRequest, *DeleteResponse) error
 	List(context.Context, *ListRequest, *ListResponse) error
+	ReadByEmail(context.Context, *ReadByEmailRequest, *ReadByEmailResponse) error
+	ReadByHandle(context.Context, *ReadByHandleRequest, *ReadByHandleResponse) error
+	VerifyCredentials(context.Context, *VerifyCredentialsRequest, *VerifyCredentialsResponse) error
+	// == Password reset ==
+	RequestPasswordReset(context.Context, *RequestPasswordResetRequest, *RequestPasswordResetResponse) error
+	ResetPassword(context.Context, *ResetPasswordRequest, *ResetPasswordResponse) error
+	// == Email verification ==
+	VerifyEmail(context.Context, *VerifyEmailRequest, *VerifyEmailResponse) error
+	ResendVerification(context.Context, *ResendVerificationRequest, *ResendVerificationResponse) error
+	// == Account changes ==
+	ChangePassword(context.Context, *ChangePasswordRequest, *ChangePasswordResponse) error
+	RequestEmailChange(context.Context, *RequestEmailChangeRequest, *RequestEmailChangeResponse) error
+	ConfirmEmailChange(context.Context, *ConfirmEmailChangeRequest, *ConfirmEmailChangeResponse) error
+	// == Personal access tokens ==
+	CreateToken(context.Context, *CreateTokenRequest, *CreateTokenResponse) error
+	ListTokens(context.Context, *ListTokensRequest, *ListTokensResponse) error
+	RevokeToken(context.Context, *RevokeTokenRequest, *RevokeTokenResponse) error
+	VerifyToken(context.Context, *VerifyTokenRequest, *VerifyTokenResponse) error
+	// == Roles ==
+	SetRoles(context.Context, *SetRolesRequest, *SetRolesResponse) error
+	// == Two-factor authentication ==
+	EnrollTOTP(context.Context, *EnrollTOTPRequest, *EnrollTOTPResponse) error
+	ConfirmTOTP(context.Context, *ConfirmTOTPRequest, *ConfirmTOTPResponse) error
+	DisableTOTP(context.Context, *DisableTOTPRequest, *DisableTOTPResponse) error
+	VerifyTOTP(context.Context, *VerifyTOTPRequest, *VerifyTOTPResponse) error
+	// == External identities ==
+	ResolveIdentity(context.Context, *ResolveIdentityRequest, *ResolveIdentityResponse) error
+	ListIdentities(context.Context, *ListIdentitiesRequest, *ListIdentitiesResponse) error
+	// == Account deletion ==
+	DeleteAccount(context.Context, *DeleteAccountRequest, *DeleteAccountResponse) error
+	ReadDeletion(context.Context, *ReadDeletionRequest, *ReadDeletionResponse) error
+	// == Follows ==
+	Follow(context.Context, *FollowRequest, *FollowResponse) error
+	Unfollow(context.Context, *UnfollowRequest, *UnfollowResponse) error
+	FollowTag(context.Context, *FollowTagRequest, *FollowTagResponse) error
+	UnfollowTag(context.Context, *UnfollowTagRequest, *UnfollowTagResponse) error
+	ListFollowing(context.Context, *ListFollowingRequest, *ListFollowingResponse) error
+	ListFollowers(context.Context, *ListFollowersRequest, *ListFollowersResponse) error
+	// == Blocks and mutes ==
+	Block(context.Context, *BlockRequest, *BlockResponse) error
+	Unblock(context.Context, *UnblockRequest, *UnblockResponse) error
+	Mute(context.Context, *MuteRequest, *MuteResponse) error
+	Unmute(context.Context, *UnmuteRequest, *UnmuteResponse) error
+	ListBlocks(context.Context, *ListBlocksRequest, *ListBlocksResponse) error
+	IsBlocked(context.Context, *IsBlockedRequest, *IsBlockedResponse) error
 }
 
 func RegisterUsersHandler(s server.Server, hdlr UsersHandler, opts ...server.HandlerOption) error {
@@ -114,6 +554,41 @@ func RegisterUsersHandler(s server.Server, hdlr UsersHandler, opts ...server.Han
 		Update(ctx context.Context, in *UpdateRequest, out *UpdateResponse) error
 		Delete(ctx context.Context, in *DeleteRequest, out *DeleteResponse) error
 		List(ctx context.Context, in *ListRequest, out *ListResponse) error
+		ReadByEmail(ctx context.Context, in *ReadByEmailRequest, out *ReadByEmailResponse) error
+		ReadByHandle(ctx context.Context, in *ReadByHandleRequest, out *ReadByHandleResponse) error
+		VerifyCredentials(ctx context.Context, in *VerifyCredentialsRequest, out *VerifyCredentialsResponse) error
+		RequestPasswordReset(ctx context.Context, in *RequestPasswordResetRequest, out *RequestPasswordResetResponse) error
+		ResetPassword(ctx context.Context, in *ResetPasswordRequest, out *ResetPasswordResponse) error
+		VerifyEmail(ctx context.Context, in *VerifyEmailRequest, out *VerifyEmailResponse) error
+		ResendVerification(ctx context.Context, in *ResendVerificationRequest, out *ResendVerificationResponse) error
+		ChangePassword(ctx context.Context, in *ChangePasswordRequest, out *ChangePasswordResponse) error
+		RequestEmailChange(ctx context.Context, in *RequestEmailChangeRequest, out *RequestEmailChangeResponse) error
+		ConfirmEmailChange(ctx context.Context, in *ConfirmEmailChangeRequest, out *ConfirmEmailChangeResponse) error
+		CreateToken(ctx context.Context, in *CreateTokenRequest, out *CreateTokenResponse) error
+		ListTokens(ctx context.Context, in *ListTokensRequest, out *ListTokensResponse) error
+		RevokeToken(ctx context.Context, in *RevokeTokenRequest, out *RevokeTokenResponse) error
+		VerifyToken(ctx context.Context, in *VerifyTokenRequest, out *VerifyTokenResponse) error
+		SetRoles(ctx context.Context, in *SetRolesRequest, out *SetRolesResponse) error
+		EnrollTOTP(ctx context.Context, in *EnrollTOTPRequest, out *EnrollTOTPResponse) error
+		ConfirmTOTP(ctx context.Context, in *ConfirmTOTPRequest, out *ConfirmTOTPResponse) error
+		DisableTOTP(ctx context.Context, in *DisableTOTPRequest, out *DisableTOTPResponse) error
+		VerifyTOTP(ctx context.Context, in *VerifyTOTPRequest, out *VerifyTOTPResponse) error
+		ResolveIdentity(ctx context.Context, in *ResolveIdentityRequest, out *ResolveIdentityResponse) error
+		ListIdentities(ctx context.Context, in *ListIdentitiesRequest, out *ListIdentitiesResponse) error
+		DeleteAccount(ctx context.Context, in *DeleteAccountRequest, out *DeleteAccountResponse) error
+		ReadDeletion(ctx context.Context, in *ReadDeletionRequest, out *ReadDeletionResponse) error
+		Follow(ctx context.Context, in *FollowRequest, out *FollowResponse) error
+		Unfollow(ctx context.Context, in *UnfollowRequest, out *UnfollowResponse) error
+		FollowTag(ctx context.Context, in *FollowTagRequest, out *FollowTagResponse) error
+		UnfollowTag(ctx context.Context, in *UnfollowTagRequest, out *UnfollowTagResponse) error
+		ListFollowing(ctx context.Context, in *ListFollowingRequest, out *ListFollowingResponse) error
+		ListFollowers(ctx context.Context, in *ListFollowersRequest, out *ListFollowersResponse) error
+		Block(ctx context.Context, in *BlockRequest, out *BlockResponse) error
+		Unblock(ctx context.Context, in *UnblockRequest, out *UnblockResponse) error
+		Mute(ctx context.Context, in *MuteRequest, out *MuteResponse) error
+		Unmute(ctx context.Context, in *UnmuteRequest, out *UnmuteResponse) error
+		ListBlocks(ctx context.Context, in *ListBlocksRequest, out *ListBlocksResponse) error
+		IsBlocked(ctx context.Context, in *IsBlockedRequest, out *IsBlockedResponse) error
 	}
 	type Users struct {
 		users
@@ -145,3 +620,143 @@ func (h *usersHandler) Delete(ctx context.Context, in *DeleteRequest, out *Delet
 func (h *usersHandler) List(ctx context.Context, in *ListRequest, out *ListResponse) error {
 	return h.UsersHandler.List(ctx, in, out)
 }
+
+func (h *usersHandler) ReadByEmail(ctx context.Context, in *ReadByEmailRequest, out *ReadByEmailResponse) error {
+	return h.UsersHandler.ReadByEmail(ctx, in, out)
+}
+
+func (h *usersHandler) ReadByHandle(ctx context.Context, in *ReadByHandleRequest, out *ReadByHandleResponse) error {
+	return h.UsersHandler.ReadByHandle(ctx, in, out)
+}
+
+func (h *usersHandler) VerifyCredentials(ctx context.Context, in *VerifyCredentialsRequest, out *VerifyCredentialsResponse) error {
+	return h.UsersHandler.VerifyCredentials(ctx, in, out)
+}
+
+func (h *usersHandler) RequestPasswordReset(ctx context.Context, in *RequestPasswordResetRequest, out *RequestPasswordResetResponse) error {
+	return h.UsersHandler.RequestPasswordReset(ctx, in, out)
+}
+
+func (h *usersHandler) ResetPassword(ctx context.Context, in *ResetPasswordRequest, out *ResetPasswordResponse) error {
+	return h.UsersHandler.ResetPassword(ctx, in, out)
+}
+
+func (h *usersHandler) VerifyEmail(ctx context.Context, in *VerifyEmailRequest, out *VerifyEmailResponse) error {
+	return h.UsersHandler.VerifyEmail(ctx, in, out)
+}
+
+func (h *usersHandler) ResendVerification(ctx context.Context, in *ResendVerificationRequest, out *ResendVerificationResponse) error {
+	return h.UsersHandler.ResendVerification(ctx, in, out)
+}
+
+func (h *usersHandler) ChangePassword(ctx context.Context, in *ChangePasswordRequest, out *ChangePasswordResponse) error {
+	return h.UsersHandler.ChangePassword(ctx, in, out)
+}
+
+func (h *usersHandler) RequestEmailChange(ctx context.Context, in *RequestEmailChangeRequest, out *RequestEmailChangeResponse) error {
+	return h.UsersHandler.RequestEmailChange(ctx, in, out)
+}
+
+func (h *usersHandler) ConfirmEmailChange(ctx context.Context, in *ConfirmEmailChangeRequest, out *ConfirmEmailChangeResponse) error {
+	return h.UsersHandler.ConfirmEmailChange(ctx, in, out)
+}
+
+func (h *usersHandler) CreateToken(ctx context.Context, in *CreateTokenRequest, out *CreateTokenResponse) error {
+	return h.UsersHandler.CreateToken(ctx, in, out)
+}
+
+func (h *usersHandler) ListTokens(ctx context.Context, in *ListTokensRequest, out *ListTokensResponse) error {
+	return h.UsersHandler.ListTokens(ctx, in, out)
+}
+
+func (h *usersHandler) RevokeToken(ctx context.Context, in *RevokeTokenRequest, out *RevokeTokenResponse) error {
+	return h.UsersHandler.RevokeToken(ctx, in, out)
+}
+
+func (h *usersHandler) VerifyToken(ctx context.Context, in *VerifyTokenRequest, out *VerifyTokenResponse) error {
+	return h.UsersHandler.VerifyToken(ctx, in, out)
+}
+
+func (h *usersHandler) SetRoles(ctx context.Context, in *SetRolesRequest, out *SetRolesResponse) error {
+	return h.UsersHandler.SetRoles(ctx, in, out)
+}
+
+func (h *usersHandler) EnrollTOTP(ctx context.Context, in *EnrollTOTPRequest, out *EnrollTOTPResponse) error {
+	return h.UsersHandler.EnrollTOTP(ctx, in, out)
+}
+
+func (h *usersHandler) ConfirmTOTP(ctx context.Context, in *ConfirmTOTPRequest, out *ConfirmTOTPResponse) error {
+	return h.UsersHandler.ConfirmTOTP(ctx, in, out)
+}
+
+func (h *usersHandler) DisableTOTP(ctx context.Context, in *DisableTOTPRequest, out *DisableTOTPResponse) error {
+	return h.UsersHandler.DisableTOTP(ctx, in, out)
+}
+
+func (h *usersHandler) VerifyTOTP(ctx context.Context, in *VerifyTOTPRequest, out *VerifyTOTPResponse) error {
+	return h.UsersHandler.VerifyTOTP(ctx, in, out)
+}
+
+func (h *usersHandler) ResolveIdentity(ctx context.Context, in *ResolveIdentityRequest, out *ResolveIdentityResponse) error {
+	return h.UsersHandler.ResolveIdentity(ctx, in, out)
+}
+
+func (h *usersHandler) ListIdentities(ctx context.Context, in *ListIdentitiesRequest, out *ListIdentitiesResponse) error {
+	return h.UsersHandler.ListIdentities(ctx, in, out)
+}
+
+func (h *usersHandler) DeleteAccount(ctx context.Context, in *DeleteAccountRequest, out *DeleteAccountResponse) error {
+	return h.UsersHandler.DeleteAccount(ctx, in, out)
+}
+
+func (h *usersHandler) ReadDeletion(ctx context.Context, in *ReadDeletionRequest, out *ReadDeletionResponse) error {
+	return h.UsersHandler.ReadDeletion(ctx, in, out)
+}
+
+func (h *usersHandler) Follow(ctx context.Context, in *FollowRequest, out *FollowResponse) error {
+	return h.UsersHandler.Follow(ctx, in, out)
+}
+
+func (h *usersHandler) Unfollow(ctx context.Context, in *UnfollowRequest, out *UnfollowResponse) error {
+	return h.UsersHandler.Unfollow(ctx, in, out)
+}
+
+func (h *usersHandler) FollowTag(ctx context.Context, in *FollowTagRequest, out *FollowTagResponse) error {
+	return h.UsersHandler.FollowTag(ctx, in, out)
+}
+
+func (h *usersHandler) UnfollowTag(ctx context.Context, in *UnfollowTagRequest, out *UnfollowTagResponse) error {
+	return h.UsersHandler.UnfollowTag(ctx, in, out)
+}
+
+func (h *usersHandler) ListFollowing(ctx context.Context, in *ListFollowingRequest, out *ListFollowingResponse) error {
+	return h.UsersHandler.ListFollowing(ctx, in, out)
+}
+
+func (h *usersHandler) ListFollowers(ctx context.Context, in *ListFollowersRequest, out *ListFollowersResponse) error {
+	return h.UsersHandler.ListFollowers(ctx, in, out)
+}
+
+func (h *usersHandler) Block(ctx context.Context, in *BlockRequest, out *BlockResponse) error {
+	return h.UsersHandler.Block(ctx, in, out)
+}
+
+func (h *usersHandler) Unblock(ctx context.Context, in *UnblockRequest, out *UnblockResponse) error {
+	return h.UsersHandler.Unblock(ctx, in, out)
+}
+
+func (h *usersHandler) Mute(ctx context.Context, in *MuteRequest, out *MuteResponse) error {
+	return h.UsersHandler.Mute(ctx, in, out)
+}
+
+func (h *usersHandler) Unmute(ctx context.Context, in *UnmuteRequest, out *UnmuteResponse) error {
+	return h.UsersHandler.Unmute(ctx, in, out)
+}
+
+func (h *usersHandler) ListBlocks(ctx context.Context, in *ListBlocksRequest, out *ListBlocksResponse) error {
+	return h.UsersHandler.ListBlocks(ctx, in, out)
+}
+
+func (h *usersHandler) IsBlocked(ctx context.Context, in *IsBlockedRequest, out *IsBlockedResponse) error {
+	return h.UsersHandler.IsBlocked(ctx, in, out)
+}
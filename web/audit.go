@@ -0,0 +1,28 @@
+package main
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+	"go-micro.dev/v5/metadata"
+
+	"github.com/micro/blog/audit/recorder"
+)
+
+// clientMetadata names the caller, when logged in, and passes on their IP
+// and user agent, so services can record them in the audit log
+func clientMetadata(c *gin.Context) metadata.Metadata {
+	md := metadata.Metadata{
+		recorder.ClientIPKey:  c.ClientIP(),
+		recorder.UserAgentKey: c.Request.UserAgent(),
+	}
+	if userID, ok := c.Get("user_id"); ok {
+		md[recorder.UserIDKey] = userID.(string)
+	}
+	return md
+}
+
+// clientContext returns a context carrying clientMetadata
+func clientContext(c *gin.Context) context.Context {
+	return metadata.NewContext(context.Background(), clientMetadata(c))
+}
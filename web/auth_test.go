@@ -0,0 +1,56 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRequireScope(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		name   string
+		scopes []string // nil for a session request
+		want   int
+	}{
+		{"session", nil, http.StatusNoContent},
+		{"unscoped token", []string{}, http.StatusNoContent},
+		{"scoped token", []string{"comments:write", "posts:write"}, http.StatusNoContent},
+		{"other scope", []string{"comments:write"}, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(func(c *gin.Context) {
+				if tt.scopes != nil {
+					c.Set("token_scopes", tt.scopes)
+				}
+			})
+			router.POST("/posts", requireScope("posts:write"), func(c *gin.Context) {
+				c.Status(http.StatusNoContent)
+			})
+			router.POST("/tokens", sessionOnly, func(c *gin.Context) {
+				c.Status(http.StatusNoContent)
+			})
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/posts", nil))
+			if w.Code != tt.want {
+				t.Errorf("POST /posts = %d, want %d", w.Code, tt.want)
+			}
+
+			// Tokens never manage tokens, whatever their scopes
+			want := http.StatusNoContent
+			if tt.scopes != nil {
+				want = http.StatusForbidden
+			}
+			w = httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/tokens", nil))
+			if w.Code != want {
+				t.Errorf("POST /tokens = %d, want %d", w.Code, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"log"
+
+	"github.com/gin-gonic/gin"
+
+	commentProto "github.com/micro/blog/comments/proto"
+	postProto "github.com/micro/blog/posts/proto"
+	userProto "github.com/micro/blog/users/proto"
+)
+
+// hiddenAuthors returns the users the current user has blocked or muted,
+// whose posts and comments are left out of listings. Anonymous requests
+// hide nothing. The result is cached on the request.
+func hiddenAuthors(c *gin.Context, users userProto.UsersService) map[string]bool {
+	if v, ok := c.Get("hidden_authors"); ok {
+		return v.(map[string]bool)
+	}
+	hidden := map[string]bool{}
+	if userID, _ := c.Get("user_id"); userID != nil {
+		resp, err := users.ListBlocks(context.Background(), &userProto.ListBlocksRequest{UserId: userID.(string)})
+		if err != nil {
+			log.Printf("Failed to list blocks for %s: %v", userID, err)
+		} else {
+			for _, id := range resp.BlockedIds {
+				hidden[id] = true
+			}
+			for _, id := range resp.MutedIds {
+				hidden[id] = true
+			}
+		}
+	}
+	c.Set("hidden_authors", hidden)
+	return hidden
+}
+
+// visiblePosts drops posts by hidden authors
+func visiblePosts(posts []*postProto.Post, hidden map[string]bool) []*postProto.Post {
+	if len(hidden) == 0 {
+		return posts
+	}
+	var visible []*postProto.Post
+	for _, p := range posts {
+		if !hidden[p.AuthorId] {
+			visible = append(visible, p)
+		}
+	}
+	return visible
+}
+
+// visibleComments drops comments by hidden authors
+func visibleComments(comments []*commentProto.Comment, hidden map[string]bool) []*commentProto.Comment {
+	if len(hidden) == 0 {
+		return comments
+	}
+	var visible []*commentProto.Comment
+	for _, c := range comments {
+		if !hidden[c.AuthorId] {
+			visible = append(visible, c)
+		}
+	}
+	return visible
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+	"go-micro.dev/v5/client"
+	"go-micro.dev/v5/errors"
+	"go-micro.dev/v5/store"
+
+	userProto "github.com/micro/blog/users/proto"
+	"github.com/micro/blog/web/session"
+)
+
+// tokenUsers accepts the access token "good" for user jane
+type tokenUsers struct {
+	userProto.UsersService
+}
+
+func (tokenUsers) VerifyToken(ctx context.Context, in *userProto.VerifyTokenRequest, opts ...client.CallOption) (*userProto.VerifyTokenResponse, error) {
+	if in.Secret != "good" {
+		return nil, errors.Unauthorized("users.VerifyToken", "invalid token")
+	}
+	return &userProto.VerifyTokenResponse{User: &userProto.User{Id: "jane"}, Token: &userProto.Token{}}, nil
+}
+
+// newCSRFRouter returns a router with sessions, bearer tokens and CSRF
+// protection in front of GET /csrf and POST /change
+func newCSRFRouter(t *testing.T) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	st := store.NewFileStore(store.DirOption(t.TempDir()))
+	t.Cleanup(func() { st.Close() })
+
+	router := gin.New()
+	router.Use(sessions.Sessions(sessionCookie, session.NewStore(st, []byte("0123456789abcdef0123456789abcdef"), time.Hour, time.Hour)))
+	router.Use(bearerAuth(tokenUsers{}))
+	router.Use(csrfProtect)
+	router.GET("/csrf", func(c *gin.Context) {
+		sess := sessions.Default(c)
+		token := csrfToken(sess)
+		sess.Save()
+		c.JSON(http.StatusOK, gin.H{"csrf_token": token})
+	})
+	router.POST("/change", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+	return router
+}
+
+// csrfSession starts a session and returns its cookie and CSRF token
+func csrfSession(t *testing.T, router *gin.Engine) (*http.Cookie, string) {
+	t.Helper()
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/csrf", nil))
+	var body struct {
+		Token string `json:"csrf_token"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&body); err != nil || body.Token == "" {
+		t.Fatalf("GET /csrf: %s %v", w.Body, err)
+	}
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 {
+		t.Fatalf("GET /csrf set %d cookies", len(cookies))
+	}
+	return cookies[0], body.Token
+}
+
+func TestCSRFProtect(t *testing.T) {
+	router := newCSRFRouter(t)
+	cookie, token := csrfSession(t, router)
+
+	tests := []struct {
+		name   string
+		method string
+		cookie bool
+		token  string
+		bearer string
+		want   int
+	}{
+		{"safe method", http.MethodGet, true, "", "", http.StatusOK},
+		{"no token", http.MethodPost, true, "", "", http.StatusForbidden},
+		{"wrong token", http.MethodPost, true, "wrong", "", http.StatusForbidden},
+		{"no session", http.MethodPost, false, token, "", http.StatusForbidden},
+		{"session token", http.MethodPost, true, token, "", http.StatusNoContent},
+		{"bearer token", http.MethodPost, false, "", "good", http.StatusNoContent},
+		{"bad bearer token", http.MethodPost, false, "", "bad", http.StatusUnauthorized},
+		// A cookie means a browser may have sent it, so the token is needed
+		{"bearer token and cookie", http.MethodPost, true, "", "good", http.StatusForbidden},
+		{"bearer token, cookie and session token", http.MethodPost, true, token, "good", http.StatusNoContent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := "/change"
+			if tt.method == http.MethodGet {
+				path = "/csrf"
+			}
+			r := httptest.NewRequest(tt.method, path, nil)
+			if tt.cookie {
+				r.AddCookie(cookie)
+			}
+			if tt.token != "" {
+				r.Header.Set(csrfHeader, tt.token)
+			}
+			if tt.bearer != "" {
+				r.Header.Set("Authorization", "Bearer "+tt.bearer)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, r)
+			if w.Code != tt.want {
+				t.Errorf("%s %s = %d %s, want %d", tt.method, path, w.Code, w.Body, tt.want)
+			}
+		})
+	}
+}
+
+func TestCSRFTokenIsPerSession(t *testing.T) {
+	router := newCSRFRouter(t)
+	cookie, _ := csrfSession(t, router)
+	_, other := csrfSession(t, router)
+
+	r := httptest.NewRequest(http.MethodPost, "/change", nil)
+	r.AddCookie(cookie)
+	r.Header.Set(csrfHeader, other)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+	if w.Code != http.StatusForbidden {
+		t.Errorf("POST with another session's token = %d, want 403", w.Code)
+	}
+}
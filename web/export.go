@@ -0,0 +1,326 @@
+package main
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	commentProto "github.com/micro/blog/comments/proto"
+	postProto "github.com/micro/blog/posts/proto"
+	userProto "github.com/micro/blog/users/proto"
+	"github.com/micro/blog/web/session"
+)
+
+// export is everything the blog holds about a user, for their personal
+// data export archive
+type export struct {
+	User       *userProto.User       `json:"user"`
+	Identities []*userProto.Identity `json:"identities"`
+	Tokens     []*userProto.Token    `json:"tokens"`
+	Sessions   []exportSession       `json:"sessions"`
+	// The user IDs and tags the user follows, and the user IDs they block
+	// and mute
+	Following    []string `json:"following"`
+	FollowedTags []string `json:"followed_tags"`
+	Blocked      []string `json:"blocked"`
+	Muted        []string `json:"muted"`
+	// Posts and comments go in files of their own
+	Posts    []*postProto.Post       `json:"-"`
+	Comments []*commentProto.Comment `json:"-"`
+	// postTitles names the posts the user commented on
+	postTitles map[string]string
+	// handles names the users the user follows, blocks and mutes
+	handles map[string]string
+}
+
+// exportSession is a session without its ID, which would let anyone
+// holding the archive use the session
+type exportSession struct {
+	CreatedAt  int64  `json:"created_at"`
+	LastSeenAt int64  `json:"last_seen_at"`
+	ExpiresAt  int64  `json:"expires_at"`
+	IP         string `json:"ip"`
+	UserAgent  string `json:"user_agent"`
+}
+
+// gatherExport collects a user's data from the users, posts and comments
+// services and the session store, including who and what they follow,
+// block and mute
+func gatherExport(ctx context.Context, userID string, users userProto.UsersService, posts postProto.PostsService, comments commentProto.CommentsService, sessions *session.Store) (*export, error) {
+	user, err := users.Read(ctx, &userProto.ReadRequest{Id: userID})
+	if err != nil {
+		return nil, err
+	}
+	if user.User == nil {
+		return nil, fmt.Errorf("user %s not found", userID)
+	}
+	identities, err := users.ListIdentities(ctx, &userProto.ListIdentitiesRequest{UserId: userID})
+	if err != nil {
+		return nil, err
+	}
+	tokens, err := users.ListTokens(ctx, &userProto.ListTokensRequest{UserId: userID})
+	if err != nil {
+		return nil, err
+	}
+	infos, err := sessions.List(userID)
+	if err != nil {
+		return nil, err
+	}
+	following, err := users.ListFollowing(ctx, &userProto.ListFollowingRequest{UserId: userID})
+	if err != nil {
+		return nil, err
+	}
+	blocks, err := users.ListBlocks(ctx, &userProto.ListBlocksRequest{UserId: userID})
+	if err != nil {
+		return nil, err
+	}
+	userPosts, err := allPosts(ctx, posts, &postProto.ListRequest{AuthorId: userID})
+	if err != nil {
+		return nil, err
+	}
+	commentList, err := comments.List(ctx, &commentProto.ListRequest{AuthorId: userID})
+	if err != nil {
+		return nil, err
+	}
+
+	e := &export{
+		User:         user.User,
+		Identities:   identities.Identities,
+		Tokens:       tokens.Tokens,
+		Following:    following.UserIds,
+		FollowedTags: following.Tags,
+		Blocked:      blocks.BlockedIds,
+		Muted:        blocks.MutedIds,
+		Posts:        userPosts,
+		Comments:     commentList.Comments,
+		postTitles:   map[string]string{},
+		handles:      map[string]string{},
+	}
+	for _, info := range infos {
+		e.Sessions = append(e.Sessions, exportSession{
+			CreatedAt:  info.CreatedAt,
+			LastSeenAt: info.LastSeenAt,
+			ExpiresAt:  info.ExpiresAt,
+			IP:         info.IP,
+			UserAgent:  info.UserAgent,
+		})
+	}
+	for _, ids := range [][]string{e.Following, e.Blocked, e.Muted} {
+		for _, id := range ids {
+			if _, ok := e.handles[id]; ok {
+				continue
+			}
+			// Users may have been deleted since
+			if rsp, err := users.Read(ctx, &userProto.ReadRequest{Id: id}); err == nil && rsp.User != nil {
+				e.handles[id] = rsp.User.Handle
+			} else {
+				e.handles[id] = ""
+			}
+		}
+	}
+	sort.Slice(e.Comments, func(i, j int) bool { return e.Comments[i].CreatedAt < e.Comments[j].CreatedAt })
+	for _, c := range e.Comments {
+		if _, ok := e.postTitles[c.PostId]; ok {
+			continue
+		}
+		// Posts may have been deleted since
+		if rsp, err := posts.Read(ctx, &postProto.ReadRequest{Id: c.PostId}); err == nil && rsp.Post != nil {
+			e.postTitles[c.PostId] = rsp.Post.Title
+		} else {
+			e.postTitles[c.PostId] = ""
+		}
+	}
+	return e, nil
+}
+
+// exportFile is a file in the export archive
+type exportFile struct {
+	name string
+	data []byte
+}
+
+// writeZip writes the export as a zip archive of JSON files with Markdown
+// renderings alongside:
+//
+//	profile.json, profile.md
+//	posts.json, posts/<date>-<id>.md
+//	comments.json, comments.md
+func (e *export) writeZip(w io.Writer, now time.Time) error {
+	profile, err := json.MarshalIndent(e, "", "  ")
+	if err != nil {
+		return err
+	}
+	posts, err := json.MarshalIndent(e.Posts, "", "  ")
+	if err != nil {
+		return err
+	}
+	comments, err := json.MarshalIndent(e.Comments, "", "  ")
+	if err != nil {
+		return err
+	}
+	files := []exportFile{
+		{"profile.json", profile},
+		{"profile.md", []byte(e.profileMarkdown())},
+		{"posts.json", posts},
+		{"comments.json", comments},
+		{"comments.md", []byte(e.commentsMarkdown())},
+	}
+	for _, p := range e.Posts {
+		files = append(files, exportFile{fmt.Sprintf("posts/%s-%s.md", date(p.CreatedAt), p.Id), []byte(postMarkdown(p))})
+	}
+
+	z := zip.NewWriter(w)
+	for _, f := range files {
+		fw, err := z.CreateHeader(&zip.FileHeader{Name: f.name, Method: zip.Deflate, Modified: now})
+		if err != nil {
+			return err
+		}
+		if _, err := fw.Write(f.data); err != nil {
+			return err
+		}
+	}
+	return z.Close()
+}
+
+func date(unix int64) string {
+	return time.Unix(unix, 0).UTC().Format("2006-01-02")
+}
+
+func timestamp(unix int64) string {
+	if unix == 0 {
+		return "never"
+	}
+	return time.Unix(unix, 0).UTC().Format(time.RFC3339)
+}
+
+func (e *export) profileMarkdown() string {
+	u := e.User
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", u.Name)
+	fmt.Fprintf(&b, "- Handle: @%s\n", u.Handle)
+	fmt.Fprintf(&b, "- Email: %s (verified: %t)\n", u.Email, u.Verified)
+	if u.DisplayName != "" {
+		fmt.Fprintf(&b, "- Display name: %s\n", u.DisplayName)
+	}
+	if u.Website != "" {
+		fmt.Fprintf(&b, "- Website: %s\n", u.Website)
+	}
+	if u.AvatarUrl != "" {
+		fmt.Fprintf(&b, "- Avatar: %s\n", u.AvatarUrl)
+	}
+	fmt.Fprintf(&b, "- Roles: %s\n", strings.Join(u.Roles, ", "))
+	fmt.Fprintf(&b, "- Two-factor authentication: %t\n", u.TwoFactor)
+	if u.CreatedAt != 0 {
+		fmt.Fprintf(&b, "- Joined: %s\n", timestamp(u.CreatedAt))
+	}
+	if u.Bio != "" {
+		fmt.Fprintf(&b, "\n%s\n", u.Bio)
+	}
+
+	if len(e.Identities) > 0 {
+		b.WriteString("\n## Linked accounts\n\n")
+		for _, id := range e.Identities {
+			fmt.Fprintf(&b, "- %s (%s), linked %s\n", id.Issuer, id.Email, timestamp(id.LinkedAt))
+		}
+	}
+	if len(e.Tokens) > 0 {
+		b.WriteString("\n## Access tokens\n\n")
+		for _, t := range e.Tokens {
+			scopes := "full access"
+			if len(t.Scopes) > 0 {
+				scopes = strings.Join(t.Scopes, ", ")
+			}
+			fmt.Fprintf(&b, "- %s (%s), created %s, last used %s\n", t.Name, scopes, timestamp(t.CreatedAt), timestamp(t.LastUsedAt))
+		}
+	}
+	if len(e.Sessions) > 0 {
+		b.WriteString("\n## Sessions\n\n")
+		for _, s := range e.Sessions {
+			fmt.Fprintf(&b, "- %s from %s, started %s, last seen %s\n", s.UserAgent, s.IP, timestamp(s.CreatedAt), timestamp(s.LastSeenAt))
+		}
+	}
+	e.usersMarkdown(&b, "Following", e.Following)
+	if len(e.FollowedTags) > 0 {
+		b.WriteString("\n## Followed tags\n\n")
+		for _, tag := range e.FollowedTags {
+			fmt.Fprintf(&b, "- #%s\n", tag)
+		}
+	}
+	e.usersMarkdown(&b, "Blocked", e.Blocked)
+	e.usersMarkdown(&b, "Muted", e.Muted)
+	fmt.Fprintf(&b, "\n%d posts and %d comments are included in this export.\n", len(e.Posts), len(e.Comments))
+	return b.String()
+}
+
+// usersMarkdown writes a section listing users by handle
+func (e *export) usersMarkdown(b *strings.Builder, heading string, ids []string) {
+	if len(ids) == 0 {
+		return
+	}
+	fmt.Fprintf(b, "\n## %s\n\n", heading)
+	for _, id := range ids {
+		if handle := e.handles[id]; handle != "" {
+			fmt.Fprintf(b, "- @%s (%s)\n", handle, id)
+		} else {
+			fmt.Fprintf(b, "- a deleted user (%s)\n", id)
+		}
+	}
+}
+
+func postMarkdown(p *postProto.Post) string {
+	status := p.Status
+	if status == "" {
+		status = "published"
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", p.Title)
+	fmt.Fprintf(&b, "- Status: %s\n", status)
+	fmt.Fprintf(&b, "- Created: %s\n", timestamp(p.CreatedAt))
+	switch {
+	case status == "scheduled":
+		fmt.Fprintf(&b, "- Scheduled for: %s\n", timestamp(p.PublishAt))
+	case p.PublishAt != 0:
+		fmt.Fprintf(&b, "- Published: %s\n", timestamp(p.PublishAt))
+	case status == "published":
+		// Posts from before statuses were published when created
+		fmt.Fprintf(&b, "- Published: %s\n", timestamp(p.CreatedAt))
+	}
+	if p.UpdatedAt != 0 && p.UpdatedAt != p.CreatedAt {
+		fmt.Fprintf(&b, "- Updated: %s\n", timestamp(p.UpdatedAt))
+	}
+	if len(p.Tags) > 0 {
+		fmt.Fprintf(&b, "- Tags: %s\n", strings.Join(p.Tags, ", "))
+	}
+	fmt.Fprintf(&b, "\n%s\n", p.Content)
+	if lp := p.LinkPreview; lp != nil && lp.Url != "" {
+		title := lp.Title
+		if title == "" {
+			title = lp.Url
+		}
+		fmt.Fprintf(&b, "\n> [%s](%s)", title, lp.Url)
+		if lp.Description != "" {
+			fmt.Fprintf(&b, "\n> %s", lp.Description)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+func (e *export) commentsMarkdown() string {
+	var b strings.Builder
+	b.WriteString("# Comments\n")
+	for _, c := range e.Comments {
+		title := e.postTitles[c.PostId]
+		if title == "" {
+			title = "a deleted post"
+		}
+		fmt.Fprintf(&b, "\n## On %s\n\n", title)
+		fmt.Fprintf(&b, "%s\n\n%s\n", timestamp(c.CreatedAt), c.Content)
+	}
+	return b.String()
+}
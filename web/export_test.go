@@ -0,0 +1,67 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	postProto "github.com/micro/blog/posts/proto"
+	userProto "github.com/micro/blog/users/proto"
+)
+
+func TestPostMarkdownStatus(t *testing.T) {
+	created := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC).Unix()
+	later := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC).Unix()
+	tests := []struct {
+		name string
+		post *postProto.Post
+		want []string
+		not  []string
+	}{
+		{"draft", &postProto.Post{Status: "draft", CreatedAt: created},
+			[]string{"- Status: draft\n", "- Created: 2026-01-02T03:04:05Z\n"}, []string{"Published"}},
+		{"scheduled", &postProto.Post{Status: "scheduled", CreatedAt: created, PublishAt: later},
+			[]string{"- Status: scheduled\n", "- Scheduled for: 2026-02-01T00:00:00Z\n"}, []string{"Published"}},
+		{"published", &postProto.Post{Status: "published", CreatedAt: created, PublishAt: later},
+			[]string{"- Status: published\n", "- Published: 2026-02-01T00:00:00Z\n"}, nil},
+		{"from before statuses", &postProto.Post{CreatedAt: created},
+			[]string{"- Status: published\n", "- Published: 2026-01-02T03:04:05Z\n"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			md := postMarkdown(tt.post)
+			for _, want := range tt.want {
+				if !strings.Contains(md, want) {
+					t.Errorf("postMarkdown = %q, want it to contain %q", md, want)
+				}
+			}
+			for _, not := range tt.not {
+				if strings.Contains(md, not) {
+					t.Errorf("postMarkdown = %q, contains %q", md, not)
+				}
+			}
+		})
+	}
+}
+
+func TestProfileMarkdownRelationships(t *testing.T) {
+	e := &export{
+		User:         &userProto.User{Name: "Jane Doe", Handle: "jane"},
+		Following:    []string{"u1", "u2"},
+		FollowedTags: []string{"go"},
+		Blocked:      []string{"u3"},
+		Muted:        []string{"u1"},
+		handles:      map[string]string{"u1": "john", "u2": "", "u3": "mallory"},
+	}
+	md := e.profileMarkdown()
+	for _, want := range []string{
+		"## Following\n\n- @john (u1)\n- a deleted user (u2)\n",
+		"## Followed tags\n\n- #go\n",
+		"## Blocked\n\n- @mallory (u3)\n",
+		"## Muted\n\n- @john (u1)\n",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("profileMarkdown = %q, want it to contain %q", md, want)
+		}
+	}
+}
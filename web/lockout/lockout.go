@@ -0,0 +1,208 @@
+// Package lockout throttles credential guessing. Failed attempts are counted
+// per key (an account, an IP address) in the go-micro store, and once a key
+// has used up its free attempts each further failure locks it for twice as
+// long as the last. Attempts count as failures from the moment they start,
+// so concurrent guesses can't all pass the check, and are taken back when
+// they succeed.
+package lockout
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go-micro.dev/v5/store"
+)
+
+// Policy controls how a kind of key is throttled
+type Policy struct {
+	// Free is the number of failures allowed before locking starts
+	Free int
+	// Base is the first lock duration; each further failure doubles it
+	Base time.Duration
+	// Max caps the lock duration
+	Max time.Duration
+	// Window is how long failures are remembered after the last one
+	Window time.Duration
+}
+
+// Entry is the failure count of one key
+type Entry struct {
+	Key            string `json:"key"`
+	Failures       int    `json:"failures"`
+	FirstFailureAt int64  `json:"first_failure_at"`
+	LastFailureAt  int64  `json:"last_failure_at"`
+	LockedUntil    int64  `json:"locked_until,omitempty"`
+}
+
+// Limiter tracks failures. Keys are "<kind>:<value>", and the kind picks
+// the policy, e.g. "account:jane@example.com" or "ip:203.0.113.7".
+type Limiter struct {
+	store    store.Store
+	policies map[string]Policy
+	// mu serializes read-modify-write of counters
+	mu sync.Mutex
+}
+
+// New returns a Limiter with a policy per key kind
+func New(st store.Store, policies map[string]Policy) *Limiter {
+	return &Limiter{store: st, policies: policies}
+}
+
+// Key builds a key of the given kind
+func Key(kind, value string) string {
+	return kind + ":" + strings.ToLower(strings.TrimSpace(value))
+}
+
+func recordKey(key string) string {
+	return "lockout-" + key
+}
+
+func (l *Limiter) policy(key string) (Policy, bool) {
+	kind, _, _ := strings.Cut(key, ":")
+	p, ok := l.policies[kind]
+	return p, ok
+}
+
+func (l *Limiter) read(key string) *Entry {
+	recs, err := l.store.Read(recordKey(key))
+	if err != nil || len(recs) == 0 {
+		return nil
+	}
+	var e Entry
+	if err := json.Unmarshal(recs[0].Value, &e); err != nil {
+		return nil
+	}
+	return &e
+}
+
+// Attempt reserves a try against each key. If any key is locked it
+// returns how long until all may be tried again and records nothing.
+// Otherwise it counts the try as a failure of every key, so concurrent
+// guesses see each other, and returns zero. Callers take the try back with
+// Reset or Forgive when it succeeds.
+func (l *Limiter) Attempt(keys ...string) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	var wait time.Duration
+	entries := make([]*Entry, len(keys))
+	for i, key := range keys {
+		entries[i] = l.read(key)
+		if entries[i] == nil {
+			continue
+		}
+		if d := time.Unix(entries[i].LockedUntil, 0).Sub(now); d > wait {
+			wait = d
+		}
+	}
+	if wait > 0 {
+		return wait
+	}
+	for i, key := range keys {
+		p, ok := l.policy(key)
+		if !ok {
+			continue
+		}
+		e := entries[i]
+		if e == nil {
+			e = &Entry{Key: key, FirstFailureAt: now.Unix()}
+		}
+		e.Failures++
+		e.LastFailureAt = now.Unix()
+		if over := e.Failures - p.Free; over > 0 {
+			e.LockedUntil = now.Add(p.lock(over)).Unix()
+		}
+		l.write(e, p)
+	}
+	return 0
+}
+
+// Forgive takes back one try from each key, for attempts that shouldn't
+// count, e.g. a successful login from an address whose other failures
+// should still be remembered
+func (l *Limiter) Forgive(keys ...string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, key := range keys {
+		p, ok := l.policy(key)
+		e := l.read(key)
+		if !ok || e == nil {
+			continue
+		}
+		e.Failures--
+		if e.Failures <= 0 {
+			_ = l.store.Delete(recordKey(key))
+			continue
+		}
+		e.LockedUntil = 0
+		if over := e.Failures - p.Free; over > 0 {
+			e.LockedUntil = time.Unix(e.LastFailureAt, 0).Add(p.lock(over)).Unix()
+		}
+		l.write(e, p)
+	}
+}
+
+// lock is how long a key is locked once it is over its free failures
+func (p Policy) lock(over int) time.Duration {
+	lock := p.Base
+	for i := 1; i < over && lock < p.Max; i++ {
+		lock *= 2
+	}
+	return min(lock, p.Max)
+}
+
+func (l *Limiter) write(e *Entry, p Policy) {
+	b, err := json.Marshal(e)
+	if err != nil {
+		return
+	}
+	_ = l.store.Write(&store.Record{Key: recordKey(e.Key), Value: b, Expiry: p.Window})
+}
+
+// Reset forgets the failures of each key, e.g. after a successful login
+func (l *Limiter) Reset(keys ...string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, key := range keys {
+		_ = l.store.Delete(recordKey(key))
+	}
+}
+
+// List returns every key with recorded failures, locked keys first
+func (l *Limiter) List() ([]*Entry, error) {
+	recs, err := l.store.Read("lockout-", store.ReadPrefix())
+	if err != nil {
+		return nil, err
+	}
+	entries := []*Entry{}
+	for _, r := range recs {
+		var e Entry
+		if err := json.Unmarshal(r.Value, &e); err == nil {
+			entries = append(entries, &e)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].LockedUntil != entries[j].LockedUntil {
+			return entries[i].LockedUntil > entries[j].LockedUntil
+		}
+		return entries[i].LastFailureAt > entries[j].LastFailureAt
+	})
+	return entries, nil
+}
+
+// Clear removes a key's failures and lock. It returns false if the key
+// had none.
+func (l *Limiter) Clear(key string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.read(key) == nil {
+		return false
+	}
+	_ = l.store.Delete(recordKey(key))
+	return true
+}
@@ -0,0 +1,152 @@
+package lockout
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go-micro.dev/v5/store"
+)
+
+var testPolicy = Policy{Free: 3, Base: 30 * time.Second, Max: 5 * time.Minute, Window: time.Hour}
+
+func newTestLimiter(t *testing.T, p Policy) *Limiter {
+	t.Helper()
+	// The file store the gateway runs with; the memory store's prefix reads
+	// return nothing without a limit
+	st := store.NewFileStore(store.DirOption(t.TempDir()))
+	t.Cleanup(func() { st.Close() })
+	return New(st, map[string]Policy{"account": p})
+}
+
+func TestPolicyLock(t *testing.T) {
+	tests := []struct {
+		over int
+		want time.Duration
+	}{
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{3, 2 * time.Minute},
+		{4, 4 * time.Minute},
+		{5, 5 * time.Minute},
+		{50, 5 * time.Minute},
+	}
+	for _, tt := range tests {
+		if got := testPolicy.lock(tt.over); got != tt.want {
+			t.Errorf("lock(%d) = %v, want %v", tt.over, got, tt.want)
+		}
+	}
+}
+
+func TestAttempt(t *testing.T) {
+	l := newTestLimiter(t, testPolicy)
+	key := Key("account", " Jane@Example.com")
+	if key != "account:jane@example.com" {
+		t.Fatalf("Key = %q", key)
+	}
+
+	// The free attempts pass, and the last of them locks the key
+	for i := 0; i < testPolicy.Free; i++ {
+		if wait := l.Attempt(key); wait != 0 {
+			t.Fatalf("attempt %d locked for %v", i+1, wait)
+		}
+	}
+	if wait := l.Attempt(key); wait != 0 {
+		t.Fatalf("attempt after the free ones locked for %v", wait)
+	}
+	wait := l.Attempt(key)
+	if wait <= 0 || wait > testPolicy.Base {
+		t.Fatalf("attempt while locked waits %v, want up to %v", wait, testPolicy.Base)
+	}
+	// Locked attempts aren't counted
+	if e := l.read(key); e.Failures != testPolicy.Free+1 {
+		t.Errorf("failures = %d, want %d", e.Failures, testPolicy.Free+1)
+	}
+
+	// Success forgets the key
+	l.Reset(key)
+	if wait := l.Attempt(key); wait != 0 {
+		t.Errorf("attempt after reset locked for %v", wait)
+	}
+
+	// Keys without a policy aren't counted
+	for i := 0; i < 10; i++ {
+		if wait := l.Attempt("other:x"); wait != 0 {
+			t.Fatalf("unthrottled key locked for %v", wait)
+		}
+	}
+}
+
+func TestForgive(t *testing.T) {
+	l := newTestLimiter(t, testPolicy)
+	key := Key("account", "jane")
+	for i := 0; i < testPolicy.Free+1; i++ {
+		l.Attempt(key)
+	}
+	if l.read(key).LockedUntil == 0 {
+		t.Fatal("key not locked after its free attempts")
+	}
+
+	// Taking back the attempt that locked the key unlocks it but keeps the
+	// earlier failures
+	l.Forgive(key)
+	if e := l.read(key); e.Failures != testPolicy.Free || e.LockedUntil != 0 {
+		t.Errorf("after Forgive: %+v, want %d failures and no lock", e, testPolicy.Free)
+	}
+	for i := 0; i < testPolicy.Free; i++ {
+		l.Forgive(key)
+	}
+	if l.read(key) != nil {
+		t.Error("entry kept after forgiving every failure")
+	}
+}
+
+func TestWindow(t *testing.T) {
+	p := testPolicy
+	p.Base, p.Window = 0, 50*time.Millisecond
+	l := newTestLimiter(t, p)
+	key := Key("account", "jane")
+	for i := 0; i < 5; i++ {
+		l.Attempt(key)
+	}
+	if len(mustList(t, l)) != 1 {
+		t.Fatal("failures not recorded")
+	}
+	time.Sleep(100 * time.Millisecond)
+	if l.read(key) != nil || len(mustList(t, l)) != 0 {
+		t.Error("failures remembered past the window")
+	}
+}
+
+func TestAttemptConcurrent(t *testing.T) {
+	l := newTestLimiter(t, testPolicy)
+	key := Key("account", "jane")
+
+	// However many guesses arrive at once, only the free ones and the one
+	// that locks the key get through
+	var passed atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if l.Attempt(key) == 0 {
+				passed.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	if got := passed.Load(); got != int32(testPolicy.Free+1) {
+		t.Errorf("%d concurrent attempts passed, want %d", got, testPolicy.Free+1)
+	}
+}
+
+func mustList(t *testing.T, l *Limiter) []*Entry {
+	t.Helper()
+	entries, err := l.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return entries
+}
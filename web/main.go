@@ -2,29 +2,98 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-contrib/sessions"
-	"github.com/gin-contrib/sessions/cookie"
 	"github.com/gin-gonic/gin"
+	"github.com/gorilla/securecookie"
 	"go-micro.dev/v5"
-	"golang.org/x/crypto/bcrypt"
+	"go-micro.dev/v5/errors"
+	"go-micro.dev/v5/store"
 
+	auditProto "github.com/micro/blog/audit/proto"
+	"github.com/micro/blog/audit/recorder"
 	commentProto "github.com/micro/blog/comments/proto"
 	postProto "github.com/micro/blog/posts/proto"
 	userProto "github.com/micro/blog/users/proto"
+	"github.com/micro/blog/web/lockout"
+	"github.com/micro/blog/web/oidc"
+	"github.com/micro/blog/web/session"
 )
 
-func hashPassword(password string) (string, error) {
-	bytes, err := bcrypt.GenerateFromPassword([]byte(password), 14)
-	return string(bytes), err
+// rpcError writes err as a JSON response, using the status code of a
+// go-micro error (e.g. 409 for a conflict) and 500 for anything else.
+func rpcError(c *gin.Context, err error) {
+	e := errors.FromError(err)
+	code := int(e.Code)
+	if code < 400 || code > 599 {
+		code = http.StatusInternalServerError
+	}
+	detail := e.Detail
+	if detail == "" {
+		detail = err.Error()
+	}
+	c.JSON(code, gin.H{"error": detail})
+}
+
+// getenv returns the value of an environment variable or a default
+func getenv(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
+
+// durationEnv parses a duration from an environment variable
+func durationEnv(key string, def time.Duration) time.Duration {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		log.Fatalf("Invalid %s %q: %v", key, v, err)
+	}
+	return d
 }
 
-func checkPasswordHash(password, hash string) bool {
-	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+// newSessionStore keeps sessions server side. SESSION_KEY signs the session
+// cookie; without it a random key is used and sessions end on restart.
+func newSessionStore() *session.Store {
+	key := []byte(os.Getenv("SESSION_KEY"))
+	if len(key) == 0 {
+		log.Println("SESSION_KEY not set, using a random key; sessions will not survive a restart")
+		key = securecookie.GenerateRandomKey(32)
+	} else if len(key) < 32 {
+		log.Fatal("SESSION_KEY must be at least 32 bytes")
+	}
+	ttl := durationEnv("SESSION_TTL", 7*24*time.Hour)
+	st := session.NewStore(
+		store.DefaultStore,
+		key,
+		ttl,
+		durationEnv("SESSION_IDLE_TIMEOUT", 24*time.Hour),
+	)
+	st.Options(cookieOptions(int(ttl.Seconds())))
+	return st
 }
 
+// sessionCookie names the session cookie
+const sessionCookie = "session"
+
+// twoFactorTimeout is how long a password login waits for its 2FA code
+const twoFactorTimeout = 5 * time.Minute
+
+// defaultPostsLimit is the page size of GET /posts without ?limit=
+const defaultPostsLimit = 20
+
 func main() {
 
 	service := micro.NewService(
@@ -35,271 +104,1572 @@ func main() {
 	postClient := postProto.NewPostsService("posts", service.Client())
 	commentClient := commentProto.NewCommentsService("comments", service.Client())
 	userClient := userProto.NewUsersService("users", service.Client())
+	auditClient := auditProto.NewAuditService("audit", service.Client())
+
+	// Logins, logouts and session changes go to the audit log; the
+	// services record changes to what they own
+	auditor := recorder.New("rest-api", service.Client())
+	audit := func(c *gin.Context, ev *auditProto.Event) {
+		auditor.Record(clientContext(c), ev)
+	}
 
 	log.Println("Starting REST API server on port 42096...")
 
-	router := gin.Default()
+	router := gin.Default()
+
+	// Only take the client address from X-Forwarded-For when it comes from
+	// a known proxy, or per-IP throttling could be bypassed
+	var proxies []string
+	if v := os.Getenv("TRUSTED_PROXIES"); v != "" {
+		proxies = strings.Split(v, ",")
+	}
+	if err := router.SetTrustedProxies(proxies); err != nil {
+		log.Fatalf("Invalid TRUSTED_PROXIES: %v", err)
+	}
+
+	// Failed logins and signups are throttled per account and per IP
+	limiter := newLimiter()
+
+	sessionStore := newSessionStore()
+	router.Use(sessions.Sessions(sessionCookie, sessionStore))
+
+	// Middleware to set user info in context
+	router.Use(func(c *gin.Context) {
+		sess := sessions.Default(c)
+		userID := sess.Get("user_id")
+		userName := sess.Get("user_name")
+		if userID != nil && userName != nil {
+			c.Set("user_id", userID)
+			c.Set("user_name", userName)
+		}
+		c.Next()
+	})
+
+	// Scripted clients can authenticate with a personal access token instead
+	router.Use(bearerAuth(userClient))
+
+	// Cookie-authenticated mutations must echo the session's CSRF token
+	router.Use(csrfProtect)
+
+	// The browser client fetches its CSRF token here before changing
+	// anything. Anonymous visitors get one too, for signup and login.
+	router.GET("/csrf", func(c *gin.Context) {
+		sess := sessions.Default(c)
+		token := csrfToken(sess)
+		if err := sess.Save(); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save session"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"csrf_token": token})
+	})
+
+	// When REQUIRE_VERIFIED_EMAIL is set, accounts that haven't confirmed
+	// their email can read but not create posts or comments
+	requireVerified := os.Getenv("REQUIRE_VERIFIED_EMAIL") == "true"
+	verified := func(c *gin.Context) {
+		userID, _ := c.Get("user_id")
+		if !requireVerified || userID == nil {
+			c.Next()
+			return
+		}
+		user, err := currentUser(c, userClient)
+		if err != nil {
+			rpcError(c, err)
+			c.Abort()
+			return
+		}
+		if user == nil || !user.Verified {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "email verification required"})
+			return
+		}
+		c.Next()
+	}
+
+	// === Posts endpoints ===
+	router.GET("/posts", func(c *gin.Context) {
+		var exclude []string
+		for id := range hiddenAuthors(c, userClient) {
+			exclude = append(exclude, id)
+		}
+		limit, _ := strconv.Atoi(c.Query("limit"))
+		if limit <= 0 {
+			limit = defaultPostsLimit
+		}
+		resp, err := postClient.List(clientContext(c), &postProto.ListRequest{
+			Limit:            int32(limit),
+			Cursor:           c.Query("cursor"),
+			AuthorId:         c.Query("author_id"),
+			ExcludeAuthorIds: exclude,
+			Status:           c.Query("status"),
+		})
+		if err != nil {
+			rpcError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, resp)
+	})
+
+	router.GET("/posts/:id", func(c *gin.Context) {
+		id := c.Param("id")
+		resp, err := postClient.Read(clientContext(c), &postProto.ReadRequest{
+			Id: id,
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, resp)
+	})
+
+	router.POST("/posts", requireScope("posts:write"), requirePermission(userClient, "posts:write"), verified, func(c *gin.Context) {
+		var req struct {
+			Title     string `json:"title"`
+			Content   string `json:"content"`
+			Status    string `json:"status"`
+			PublishAt int64  `json:"publish_at"`
+		}
+		if err := c.BindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		userID, _ := c.Get("user_id")
+		userName, _ := c.Get("user_name")
+		if userID == nil || userName == nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "login required"})
+			return
+		}
+		user, err := currentUser(c, userClient)
+		if err != nil {
+			rpcError(c, err)
+			return
+		}
+		resp, err := postClient.Create(clientContext(c), &postProto.CreateRequest{
+			Title:        req.Title,
+			Content:      req.Content,
+			AuthorId:     userID.(string),
+			AuthorName:   userName.(string),
+			AuthorHandle: user.GetHandle(),
+			Status:       req.Status,
+			PublishAt:    req.PublishAt,
+		})
+		if err != nil {
+			rpcError(c, err)
+			return
+		}
+		c.JSON(http.StatusCreated, resp)
+	})
+
+	router.PUT("/posts/:id", requireScope("posts:write"), func(c *gin.Context) {
+		// Title and content are left alone when omitted
+		var req struct {
+			Title     *string `json:"title"`
+			Content   *string `json:"content"`
+			Status    string  `json:"status"`
+			PublishAt int64   `json:"publish_at"`
+		}
+		if err := c.BindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if _, ok := c.Get("user_id"); !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "login required"})
+			return
+		}
+		ctx, err := callerContext(c, userClient)
+		if err != nil {
+			rpcError(c, err)
+			return
+		}
+		resp, err := postClient.Update(ctx, &postProto.UpdateRequest{
+			Id:        c.Param("id"),
+			Title:     req.Title,
+			Content:   req.Content,
+			Status:    req.Status,
+			PublishAt: req.PublishAt,
+		})
+		if err != nil {
+			rpcError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, resp)
+	})
+
+	router.DELETE("/posts/:id", requireScope("posts:write"), func(c *gin.Context) {
+		if _, ok := c.Get("user_id"); !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "login required"})
+			return
+		}
+		ctx, err := callerContext(c, userClient)
+		if err != nil {
+			rpcError(c, err)
+			return
+		}
+		if _, err := postClient.Delete(ctx, &postProto.DeleteRequest{Id: c.Param("id")}); err != nil {
+			rpcError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"message": "post deleted"})
+	})
+
+	// === Post revisions ===
+	// Every edit is kept; only the author and moderators can see them
+	router.GET("/posts/:id/revisions", func(c *gin.Context) {
+		if _, ok := c.Get("user_id"); !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "login required"})
+			return
+		}
+		ctx, err := callerContext(c, userClient)
+		if err != nil {
+			rpcError(c, err)
+			return
+		}
+		resp, err := postClient.ListRevisions(ctx, &postProto.ListRevisionsRequest{PostId: c.Param("id")})
+		if err != nil {
+			rpcError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"revisions": resp.Revisions})
+	})
+
+	router.GET("/posts/:id/revisions/diff", func(c *gin.Context) {
+		if _, ok := c.Get("user_id"); !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "login required"})
+			return
+		}
+		var from, to int
+		var err error
+		if v := c.Query("from"); v != "" {
+			if from, err = strconv.Atoi(v); err != nil || from < 1 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid from"})
+				return
+			}
+		}
+		if v := c.Query("to"); v != "" {
+			if to, err = strconv.Atoi(v); err != nil || to < 1 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid to"})
+				return
+			}
+		}
+		ctx, err := callerContext(c, userClient)
+		if err != nil {
+			rpcError(c, err)
+			return
+		}
+		resp, err := postClient.DiffRevisions(ctx, &postProto.DiffRevisionsRequest{
+			PostId: c.Param("id"),
+			From:   int32(from),
+			To:     int32(to),
+		})
+		if err != nil {
+			rpcError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, resp)
+	})
+
+	router.POST("/posts/:id/revisions/:number/restore", requireScope("posts:write"), func(c *gin.Context) {
+		if _, ok := c.Get("user_id"); !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "login required"})
+			return
+		}
+		number, err := strconv.Atoi(c.Param("number"))
+		if err != nil || number < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid revision number"})
+			return
+		}
+		ctx, err := callerContext(c, userClient)
+		if err != nil {
+			rpcError(c, err)
+			return
+		}
+		resp, err := postClient.RestoreRevision(ctx, &postProto.RestoreRevisionRequest{
+			PostId: c.Param("id"),
+			Number: int32(number),
+		})
+		if err != nil {
+			rpcError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, resp)
+	})
+
+	// === Comments endpoints ===
+	router.GET("/comments", func(c *gin.Context) {
+		postID := c.Query("post_id")
+		resp, err := commentClient.List(context.Background(), &commentProto.ListRequest{
+			PostId:   postID,
+			AuthorId: c.Query("author_id"),
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		resp.Comments = visibleComments(resp.Comments, hiddenAuthors(c, userClient))
+		resp.Total = int32(len(resp.Comments))
+		c.JSON(http.StatusOK, resp)
+	})
+
+	router.POST("/comments", requireScope("comments:write"), requirePermission(userClient, "comments:write"), verified, func(c *gin.Context) {
+		var req struct {
+			Content string `json:"content"`
+			PostId  string `json:"post_id"`
+		}
+		if err := c.BindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		userID, _ := c.Get("user_id")
+		userName, _ := c.Get("user_name")
+		if userID == nil || userName == nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "login required"})
+			return
+		}
+		user, err := currentUser(c, userClient)
+		if err != nil {
+			rpcError(c, err)
+			return
+		}
+		resp, err := commentClient.Create(clientContext(c), &commentProto.CreateRequest{
+			Content:      req.Content,
+			AuthorId:     userID.(string),
+			AuthorName:   userName.(string),
+			AuthorHandle: user.GetHandle(),
+			PostId:       req.PostId,
+		})
+		if err != nil {
+			rpcError(c, err)
+			return
+		}
+		c.JSON(http.StatusCreated, resp)
+	})
+
+	router.PUT("/comments/:id", requireScope("comments:write"), func(c *gin.Context) {
+		var req struct {
+			Content string `json:"content"`
+		}
+		if err := c.BindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if _, ok := c.Get("user_id"); !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "login required"})
+			return
+		}
+		ctx, err := callerContext(c, userClient)
+		if err != nil {
+			rpcError(c, err)
+			return
+		}
+		resp, err := commentClient.Update(ctx, &commentProto.UpdateRequest{
+			Id:      c.Param("id"),
+			Content: req.Content,
+		})
+		if err != nil {
+			rpcError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, resp)
+	})
+
+	router.DELETE("/comments/:id", requireScope("comments:write"), func(c *gin.Context) {
+		if _, ok := c.Get("user_id"); !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "login required"})
+			return
+		}
+		ctx, err := callerContext(c, userClient)
+		if err != nil {
+			rpcError(c, err)
+			return
+		}
+		if _, err := commentClient.Delete(ctx, &commentProto.DeleteRequest{Id: c.Param("id")}); err != nil {
+			rpcError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"message": "comment deleted"})
+	})
+
+	// === Users endpoints ===
+	router.GET("/users", func(c *gin.Context) {
+		resp, err := userClient.List(context.Background(), &userProto.ListRequest{
+			Page:  1,
+			Limit: 10,
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if hasPermission(c, userClient, "users:admin") {
+			c.JSON(http.StatusOK, resp)
+			return
+		}
+		list := make([]gin.H, 0, len(resp.Users))
+		for _, u := range resp.Users {
+			list = append(list, publicUser(u))
+		}
+		c.JSON(http.StatusOK, gin.H{"users": list, "total": resp.Total})
+	})
+
+	// Delete the current account. Posts and comments are deleted or
+	// anonymized in the background; poll /deletions/:id for progress.
+	router.DELETE("/users/me", sessionOnly, func(c *gin.Context) {
+		var req struct {
+			Mode string `json:"mode"`
+		}
+		if err := c.BindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		userID, _ := c.Get("user_id")
+		resp, err := userClient.DeleteAccount(clientContext(c), &userProto.DeleteAccountRequest{
+			UserId: userID.(string),
+			Mode:   req.Mode,
+		})
+		if err != nil {
+			rpcError(c, err)
+			return
+		}
+		// Log the account out everywhere
+		_ = sessionStore.RevokeAll(userID.(string), "")
+		sess := sessions.Default(c)
+		sess.Clear()
+		sess.Options(cookieOptions(-1))
+		sess.Save()
+		c.JSON(http.StatusAccepted, gin.H{"deletion": resp.Deletion})
+	})
+
+	// Progress of an account deletion. The ID is only known to the user
+	// who deleted their account.
+	router.GET("/deletions/:id", func(c *gin.Context) {
+		resp, err := userClient.ReadDeletion(context.Background(), &userProto.ReadDeletionRequest{Id: c.Param("id")})
+		if err != nil {
+			rpcError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"deletion": resp.Deletion})
+	})
+
+	// Public profile by handle, with post and comment counts
+	router.GET("/users/by-handle/:handle", func(c *gin.Context) {
+		resp, err := userClient.ReadByHandle(context.Background(), &userProto.ReadByHandleRequest{
+			Handle: c.Param("handle"),
+		})
+		if err != nil {
+			rpcError(c, err)
+			return
+		}
+		u := resp.User
+		// Only the total is needed. Count published posts, not the drafts
+		// the author sees when viewing their own profile.
+		posts, err := postClient.List(clientContext(c), &postProto.ListRequest{AuthorId: u.Id, Status: "published", Limit: 1})
+		if err != nil {
+			rpcError(c, err)
+			return
+		}
+		comments, err := commentClient.List(context.Background(), &commentProto.ListRequest{AuthorId: u.Id})
+		if err != nil {
+			rpcError(c, err)
+			return
+		}
+		following, err := userClient.ListFollowing(context.Background(), &userProto.ListFollowingRequest{UserId: u.Id})
+		if err != nil {
+			rpcError(c, err)
+			return
+		}
+		followers, err := userClient.ListFollowers(context.Background(), &userProto.ListFollowersRequest{UserId: u.Id})
+		if err != nil {
+			rpcError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"profile":         publicUser(u),
+			"post_count":      posts.Total,
+			"comment_count":   comments.Total,
+			"follower_count":  len(followers.UserIds),
+			"following_count": len(following.UserIds),
+		})
+	})
+
+	router.GET("/users/:id", func(c *gin.Context) {
+		id := c.Param("id")
+		resp, err := userClient.Read(context.Background(), &userProto.ReadRequest{
+			Id: id,
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if seesFullUser(c, userClient, resp.User) {
+			c.JSON(http.StatusOK, resp)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"user": publicUser(resp.User)})
+	})
+
+	router.POST("/users", requirePermission(userClient, "users:admin"), func(c *gin.Context) {
+		var req struct {
+			Name   string `json:"name"`
+			Email  string `json:"email"`
+			Handle string `json:"handle"`
+		}
+
+		if err := c.BindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		resp, err := userClient.Create(clientContext(c), &userProto.CreateRequest{
+			Name:   req.Name,
+			Email:  req.Email,
+			Handle: req.Handle,
+		})
+		if err != nil {
+			rpcError(c, err)
+			return
+		}
+		c.JSON(http.StatusCreated, resp)
+	})
+
+	// Replace a user's roles
+	router.PUT("/users/:id/roles", sessionOnly, requirePermission(userClient, "users:admin"), func(c *gin.Context) {
+		var req struct {
+			Roles []string `json:"roles"`
+		}
+		if err := c.BindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		resp, err := userClient.SetRoles(clientContext(c), &userProto.SetRolesRequest{
+			Id:    c.Param("id"),
+			Roles: req.Roles,
+		})
+		if err != nil {
+			rpcError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, resp)
+	})
+
+	// Signup endpoint
+	router.POST("/signup", func(c *gin.Context) {
+		var req struct {
+			Name     string `json:"name"`
+			Email    string `json:"email"`
+			Password string `json:"password"`
+			Handle   string `json:"handle"`
+		}
+
+		if err := c.BindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if req.Name == "" || req.Email == "" || req.Password == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "all fields required"})
+			return
+		}
+		signupKey := lockout.Key("signup", c.ClientIP())
+		if throttled(c, limiter, signupKey) {
+			return
+		}
+		// Every signup counts, successful or not, so the attempt throttled
+		// reserved is kept
+		resp, err := userClient.Create(clientContext(c), &userProto.CreateRequest{
+			Name:     req.Name,
+			Email:    req.Email,
+			Password: req.Password,
+			Handle:   req.Handle,
+		})
+		if err != nil {
+			rpcError(c, err)
+			return
+		}
+		sess := sessions.Default(c)
+		sess.Set("user_id", resp.User.Id)
+		sess.Set("user_name", resp.User.Name)
+		sess.Save()
+		c.JSON(http.StatusCreated, gin.H{"user": resp.User})
+	})
+
+	// Login endpoint
+	router.POST("/login", func(c *gin.Context) {
+		var req struct {
+			Email    string `json:"email"`
+			Password string `json:"password"`
+		}
+		if err := c.BindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		accountKey, ipKey := lockout.Key("account", req.Email), lockout.Key("ip", c.ClientIP())
+		if throttled(c, limiter, accountKey, ipKey) {
+			return
+		}
+		// The users service checks the password against the stored hash
+		rec, err := userClient.VerifyCredentials(context.Background(), &userProto.VerifyCredentialsRequest{
+			Email:    req.Email,
+			Password: req.Password,
+		})
+		if err != nil {
+			if settle(limiter, err, accountKey, ipKey) {
+				audit(c, &auditProto.Event{Action: "auth.login_failed", Details: map[string]string{"email": req.Email}})
+			}
+			rpcError(c, err)
+			return
+		}
+		settle(limiter, nil, accountKey, ipKey)
+		found := rec.User
+		sess := sessions.Default(c)
+		// With 2FA on, the password only earns a pending login that
+		// POST /login/2fa completes
+		if found.TwoFactor {
+			sess.Delete("user_id")
+			sess.Delete("user_name")
+			sess.Set("pending_user_id", found.Id)
+			sess.Set("pending_at", time.Now().Unix())
+			sess.Save()
+			c.JSON(http.StatusOK, gin.H{"two_factor_required": true})
+			return
+		}
+		sess.Set("user_id", found.Id)
+		sess.Set("user_name", found.Name)
+		sess.Save()
+		audit(c, &auditProto.Event{Action: "auth.login", ActorId: found.Id, TargetType: "user", TargetId: found.Id,
+			Details: map[string]string{"method": "password"}})
+		c.JSON(http.StatusOK, gin.H{"user": found})
+	})
+
+	// Second login step for accounts with 2FA
+	router.POST("/login/2fa", func(c *gin.Context) {
+		var req struct {
+			Code string `json:"code"`
+		}
+		if err := c.BindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		sess := sessions.Default(c)
+		pendingID, _ := sess.Get("pending_user_id").(string)
+		pendingAt, _ := sess.Get("pending_at").(int64)
+		if pendingID == "" || time.Since(time.Unix(pendingAt, 0)) > twoFactorTimeout {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "no login in progress"})
+			return
+		}
+		accountKey, ipKey := lockout.Key("account", pendingID), lockout.Key("ip", c.ClientIP())
+		if throttled(c, limiter, accountKey, ipKey) {
+			return
+		}
+		resp, err := userClient.VerifyTOTP(context.Background(), &userProto.VerifyTOTPRequest{
+			UserId: pendingID,
+			Code:   req.Code,
+		})
+		if err != nil {
+			if settle(limiter, err, accountKey, ipKey) {
+				audit(c, &auditProto.Event{Action: "auth.2fa_failed", ActorId: pendingID, TargetType: "user", TargetId: pendingID})
+			}
+			rpcError(c, err)
+			return
+		}
+		settle(limiter, nil, accountKey, ipKey)
+		sess.Delete("pending_user_id")
+		sess.Delete("pending_at")
+		sess.Set("user_id", resp.User.Id)
+		sess.Set("user_name", resp.User.Name)
+		sess.Save()
+		audit(c, &auditProto.Event{Action: "auth.login", ActorId: resp.User.Id, TargetType: "user", TargetId: resp.User.Id,
+			Details: map[string]string{"method": "2fa"}})
+		c.JSON(http.StatusOK, gin.H{"user": resp.User, "recovery_codes_left": resp.RecoveryCodesLeft})
+	})
+
+	// Logout endpoint
+	router.POST("/logout", func(c *gin.Context) {
+		if userID, ok := c.Get("user_id"); ok {
+			audit(c, &auditProto.Event{Action: "auth.logout", TargetType: "user", TargetId: userID.(string)})
+		}
+		sess := sessions.Default(c)
+		sess.Clear()
+		// Delete the server side session along with the cookie
+		sess.Options(cookieOptions(-1))
+		sess.Save()
+		c.JSON(http.StatusOK, gin.H{"message": "logged out"})
+	})
+
+	// === Password reset ===
+	// Email a single-use reset link. Always succeeds so callers can't
+	// discover which emails are registered.
+	router.POST("/password/forgot", func(c *gin.Context) {
+		var req struct {
+			Email string `json:"email"`
+		}
+		if err := c.BindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if req.Email == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "email required"})
+			return
+		}
+		// Every request counts, so no one can flood an address with mail
+		if throttled(c, limiter, lockout.Key("mail", req.Email), lockout.Key("mailip", c.ClientIP())) {
+			return
+		}
+		_, err := userClient.RequestPasswordReset(clientContext(c), &userProto.RequestPasswordResetRequest{
+			Email: req.Email,
+		})
+		if err != nil {
+			rpcError(c, err)
+			return
+		}
+		c.JSON(http.StatusAccepted, gin.H{"message": "if the account exists, a reset link has been sent"})
+	})
+
+	// Set a new password using the token from the reset email
+	router.POST("/password/reset", func(c *gin.Context) {
+		var req struct {
+			Token    string `json:"token"`
+			Password string `json:"password"`
+		}
+		if err := c.BindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if req.Token == "" || req.Password == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "token and password required"})
+			return
+		}
+		resp, err := userClient.ResetPassword(clientContext(c), &userProto.ResetPasswordRequest{
+			Token:    req.Token,
+			Password: req.Password,
+		})
+		if err != nil {
+			rpcError(c, err)
+			return
+		}
+		// Whoever knew the old password is logged out
+		_ = sessionStore.RevokeAll(resp.UserId, "")
+		c.JSON(http.StatusOK, gin.H{"message": "password updated"})
+	})
+
+	// === Email verification ===
+	// Confirm an email address from the link sent at signup
+	router.GET("/verify", func(c *gin.Context) {
+		_, err := userClient.VerifyEmail(context.Background(), &userProto.VerifyEmailRequest{
+			Token: c.Query("token"),
+		})
+		if err != nil {
+			rpcError(c, err)
+			return
+		}
+		c.Redirect(http.StatusFound, "/?verified=1")
+	})
+
+	// Send a fresh verification link to the logged in user
+	router.POST("/verify/resend", func(c *gin.Context) {
+		userID, _ := c.Get("user_id")
+		if userID == nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "login required"})
+			return
+		}
+		user, err := currentUser(c, userClient)
+		if err != nil {
+			rpcError(c, err)
+			return
+		}
+		if throttled(c, limiter, lockout.Key("mail", user.GetEmail()), lockout.Key("mailip", c.ClientIP())) {
+			return
+		}
+		_, err = userClient.ResendVerification(context.Background(), &userProto.ResendVerificationRequest{
+			UserId: userID.(string),
+		})
+		if err != nil {
+			rpcError(c, err)
+			return
+		}
+		c.JSON(http.StatusAccepted, gin.H{"message": "verification email sent"})
+	})
+
+	// Session info endpoint for frontend
+	router.GET("/users/me", func(c *gin.Context) {
+		userID, _ := c.Get("user_id")
+		userName, _ := c.Get("user_name")
+		if userID == nil || userName == nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"user": nil})
+			return
+		}
+		user, _ := currentUser(c, userClient)
+		c.JSON(http.StatusOK, gin.H{"user": gin.H{
+			"id":     userID,
+			"name":   userName,
+			"handle": user.GetHandle(),
+			"roles":  user.GetRoles(),
+		}})
+	})
+
+	// Update the current user's name, handle and profile. Omitted fields
+	// are left unchanged.
+	router.PUT("/users/me", sessionOnly, func(c *gin.Context) {
+		var req struct {
+			Name        *string `json:"name"`
+			Handle      string  `json:"handle"`
+			DisplayName *string `json:"display_name"`
+			Bio         *string `json:"bio"`
+			AvatarURL   *string `json:"avatar_url"`
+			Website     *string `json:"website"`
+		}
+		if err := c.BindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		user, err := currentUser(c, userClient)
+		if err != nil {
+			rpcError(c, err)
+			return
+		}
+		if user == nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "login required"})
+			return
+		}
+		name := user.Name
+		if req.Name != nil {
+			name = *req.Name
+		}
+		resp, err := userClient.Update(clientContext(c), &userProto.UpdateRequest{
+			Id:          user.Id,
+			Name:        name,
+			Handle:      req.Handle,
+			DisplayName: req.DisplayName,
+			Bio:         req.Bio,
+			AvatarUrl:   req.AvatarURL,
+			Website:     req.Website,
+		})
+		if err != nil {
+			rpcError(c, err)
+			return
+		}
+		sess := sessions.Default(c)
+		sess.Set("user_name", resp.User.Name)
+		sess.Save()
+		c.JSON(http.StatusOK, gin.H{"user": resp.User})
+	})
+
+	// Change the password, re-authenticating with the current one. Every
+	// other session is logged out.
+	router.POST("/users/me/password", sessionOnly, func(c *gin.Context) {
+		userID, _ := c.Get("user_id")
+		if userID == nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "login required"})
+			return
+		}
+		var req struct {
+			CurrentPassword string `json:"current_password"`
+			NewPassword     string `json:"new_password"`
+		}
+		if err := c.BindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		accountKey, ipKey := lockout.Key("account", userID.(string)), lockout.Key("ip", c.ClientIP())
+		if throttled(c, limiter, accountKey, ipKey) {
+			return
+		}
+		_, err := userClient.ChangePassword(clientContext(c), &userProto.ChangePasswordRequest{
+			UserId:          userID.(string),
+			CurrentPassword: req.CurrentPassword,
+			NewPassword:     req.NewPassword,
+		})
+		if err != nil {
+			settle(limiter, err, accountKey, ipKey)
+			rpcError(c, err)
+			return
+		}
+		settle(limiter, nil, accountKey, ipKey)
+		_ = sessionStore.RevokeAll(userID.(string), sessions.Default(c).ID())
+		c.JSON(http.StatusOK, gin.H{"message": "password updated"})
+	})
+
+	// Start an email change, re-authenticating with the password. The new
+	// address gets a confirmation link; until it's followed the account
+	// keeps its current email.
+	router.POST("/users/me/email", sessionOnly, func(c *gin.Context) {
+		userID, _ := c.Get("user_id")
+		if userID == nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "login required"})
+			return
+		}
+		var req struct {
+			Email    string `json:"email"`
+			Password string `json:"password"`
+		}
+		if err := c.BindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		accountKey, ipKey := lockout.Key("account", userID.(string)), lockout.Key("ip", c.ClientIP())
+		if throttled(c, limiter, accountKey, ipKey) {
+			return
+		}
+		_, err := userClient.RequestEmailChange(clientContext(c), &userProto.RequestEmailChangeRequest{
+			UserId:   userID.(string),
+			Password: req.Password,
+			Email:    req.Email,
+		})
+		if err != nil {
+			settle(limiter, err, accountKey, ipKey)
+			rpcError(c, err)
+			return
+		}
+		settle(limiter, nil, accountKey, ipKey)
+		c.JSON(http.StatusAccepted, gin.H{"message": "confirmation link sent to the new address"})
+	})
+
+	// Finish an email change from the emailed link, which may be opened in
+	// any browser. Every session of the account other than this one is
+	// logged out.
+	router.GET("/users/me/email/confirm", func(c *gin.Context) {
+		resp, err := userClient.ConfirmEmailChange(clientContext(c), &userProto.ConfirmEmailChangeRequest{
+			Token: c.Query("token"),
+		})
+		if err != nil {
+			rpcError(c, err)
+			return
+		}
+		except := ""
+		if userID, _ := c.Get("user_id"); userID == resp.User.Id {
+			except = sessions.Default(c).ID()
+		}
+		_ = sessionStore.RevokeAll(resp.User.Id, except)
+		c.Redirect(http.StatusFound, "/?email_changed=1")
+	})
+
+	// === Personal access tokens ===
+	// Create a named token. The secret is only returned in this response.
+	router.POST("/users/me/tokens", sessionOnly, func(c *gin.Context) {
+		userID, _ := c.Get("user_id")
+		if userID == nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "login required"})
+			return
+		}
+		var req struct {
+			Name   string   `json:"name"`
+			Scopes []string `json:"scopes"`
+		}
+		if err := c.BindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		for _, s := range req.Scopes {
+			if !tokenScopes[s] {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "unknown scope " + s})
+				return
+			}
+		}
+		resp, err := userClient.CreateToken(clientContext(c), &userProto.CreateTokenRequest{
+			UserId: userID.(string),
+			Name:   req.Name,
+			Scopes: req.Scopes,
+		})
+		if err != nil {
+			rpcError(c, err)
+			return
+		}
+		c.JSON(http.StatusCreated, resp)
+	})
+
+	// List the current user's tokens (without secrets)
+	router.GET("/users/me/tokens", sessionOnly, func(c *gin.Context) {
+		userID, _ := c.Get("user_id")
+		if userID == nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "login required"})
+			return
+		}
+		resp, err := userClient.ListTokens(context.Background(), &userProto.ListTokensRequest{
+			UserId: userID.(string),
+		})
+		if err != nil {
+			rpcError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, resp)
+	})
+
+	// Revoke one of the current user's tokens
+	router.DELETE("/users/me/tokens/:id", sessionOnly, func(c *gin.Context) {
+		userID, _ := c.Get("user_id")
+		if userID == nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "login required"})
+			return
+		}
+		_, err := userClient.RevokeToken(clientContext(c), &userProto.RevokeTokenRequest{
+			UserId: userID.(string),
+			Id:     c.Param("id"),
+		})
+		if err != nil {
+			rpcError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"message": "token revoked"})
+	})
+
+	// === External login (OpenID Connect) ===
+	providers := map[string]*oidc.Provider{}
+	configured, err := oidc.FromEnv(getenv("BLOG_URL", "http://localhost:42096"))
+	if err != nil {
+		log.Fatalf("Invalid OIDC configuration: %v", err)
+	}
+	for _, p := range configured {
+		providers[p.Name] = p
+	}
+
+	// loginFailed sends the browser back to the login page with a message
+	loginFailed := func(c *gin.Context, msg string) {
+		c.Redirect(http.StatusFound, "/login.html?error="+url.QueryEscape(msg))
+	}
+
+	// List the configured providers for the login page
+	router.GET("/auth/providers", func(c *gin.Context) {
+		list := []gin.H{}
+		for _, p := range configured {
+			list = append(list, gin.H{"name": p.Name, "label": p.Label})
+		}
+		c.JSON(http.StatusOK, gin.H{"providers": list})
+	})
+
+	// Start a login, or connect the provider to the logged in account
+	router.GET("/auth/:provider/login", func(c *gin.Context) {
+		p, ok := providers[c.Param("provider")]
+		if !ok {
+			c.JSON(http.StatusNotFound, gin.H{"error": "unknown provider"})
+			return
+		}
+		state, nonce, verifier := oidc.RandomString(), oidc.RandomString(), oidc.RandomString()
+		authURL, err := p.AuthURL(c.Request.Context(), state, nonce, verifier)
+		if err != nil {
+			log.Printf("OIDC %s: %v", p.Name, err)
+			loginFailed(c, p.Label+" is unavailable")
+			return
+		}
+		sess := sessions.Default(c)
+		sess.Set("oidc_provider", p.Name)
+		sess.Set("oidc_state", state)
+		sess.Set("oidc_nonce", nonce)
+		sess.Set("oidc_verifier", verifier)
+		sess.Save()
+		c.Redirect(http.StatusFound, authURL)
+	})
+
+	// The provider redirects back here with an authorization code
+	router.GET("/auth/:provider/callback", func(c *gin.Context) {
+		p, ok := providers[c.Param("provider")]
+		if !ok {
+			c.JSON(http.StatusNotFound, gin.H{"error": "unknown provider"})
+			return
+		}
+		sess := sessions.Default(c)
+		name, _ := sess.Get("oidc_provider").(string)
+		state, _ := sess.Get("oidc_state").(string)
+		nonce, _ := sess.Get("oidc_nonce").(string)
+		verifier, _ := sess.Get("oidc_verifier").(string)
+		// The state is single use
+		sess.Delete("oidc_provider")
+		sess.Delete("oidc_state")
+		sess.Delete("oidc_nonce")
+		sess.Delete("oidc_verifier")
+		sess.Save()
 
-	sessionStore := cookie.NewStore([]byte("secret"))
-	router.Use(sessions.Sessions("session", sessionStore))
+		if name != p.Name || state == "" || c.Query("state") != state {
+			loginFailed(c, "Login expired, please try again")
+			return
+		}
+		if e := c.Query("error"); e != "" {
+			loginFailed(c, p.Label+" login failed: "+e)
+			return
+		}
+		claims, err := p.Exchange(c.Request.Context(), c.Query("code"), verifier, nonce)
+		if err != nil {
+			log.Printf("OIDC %s: %v", p.Name, err)
+			loginFailed(c, p.Label+" login failed")
+			return
+		}
 
-	// Middleware to set user info in context
-	router.Use(func(c *gin.Context) {
-		sess := sessions.Default(c)
-		userID := sess.Get("user_id")
-		userName := sess.Get("user_name")
-		if userID != nil && userName != nil {
-			c.Set("user_id", userID)
-			c.Set("user_name", userName)
+		// A logged in user is connecting the provider to their account
+		current, _ := sess.Get("user_id").(string)
+		resp, err := userClient.ResolveIdentity(clientContext(c), &userProto.ResolveIdentityRequest{
+			Issuer:            claims.Issuer,
+			Subject:           claims.Subject,
+			Email:             claims.Email,
+			EmailVerified:     claims.EmailVerified,
+			Name:              claims.Name,
+			PreferredUsername: claims.PreferredUsername,
+			UserId:            current,
+			TrustEmail:        p.TrustEmail,
+		})
+		if err != nil {
+			loginFailed(c, errors.FromError(err).Detail)
+			return
 		}
-		c.Next()
+		if current != "" {
+			c.Redirect(http.StatusFound, "/")
+			return
+		}
+		user := resp.User
+		if user.TwoFactor {
+			sess.Set("pending_user_id", user.Id)
+			sess.Set("pending_at", time.Now().Unix())
+			sess.Save()
+			c.Redirect(http.StatusFound, "/login.html?two_factor=1")
+			return
+		}
+		sess.Set("user_id", user.Id)
+		sess.Set("user_name", user.Name)
+		sess.Save()
+		audit(c, &auditProto.Event{Action: "auth.login", ActorId: user.Id, TargetType: "user", TargetId: user.Id,
+			Details: map[string]string{"method": "oidc", "provider": p.Name}})
+		c.Redirect(http.StatusFound, "/")
 	})
 
-	// === Posts endpoints ===
-	router.GET("/posts", func(c *gin.Context) {
-		resp, err := postClient.List(context.Background(), &postProto.ListRequest{
-			Page:  1,
-			Limit: 10,
-		})
+	// List the external accounts linked to the current user
+	router.GET("/users/me/identities", sessionOnly, func(c *gin.Context) {
+		userID, _ := c.Get("user_id")
+		if userID == nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "login required"})
+			return
+		}
+		resp, err := userClient.ListIdentities(context.Background(), &userProto.ListIdentitiesRequest{UserId: userID.(string)})
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			rpcError(c, err)
 			return
 		}
 		c.JSON(http.StatusOK, resp)
 	})
 
-	router.GET("/posts/:id", func(c *gin.Context) {
-		id := c.Param("id")
-		resp, err := postClient.Read(context.Background(), &postProto.ReadRequest{
-			Id: id,
+	// === Follows and the home feed ===
+	router.POST("/users/:id/follow", requireScope("follows:write"), func(c *gin.Context) {
+		userID, _ := c.Get("user_id")
+		if userID == nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "login required"})
+			return
+		}
+		_, err := userClient.Follow(context.Background(), &userProto.FollowRequest{
+			FollowerId: userID.(string),
+			FolloweeId: c.Param("id"),
 		})
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			rpcError(c, err)
 			return
 		}
-		c.JSON(http.StatusOK, resp)
+		c.JSON(http.StatusOK, gin.H{"message": "following"})
 	})
 
-	router.POST("/posts", func(c *gin.Context) {
-		var req struct {
-			Title   string `json:"title"`
-			Content string `json:"content"`
+	router.DELETE("/users/:id/follow", requireScope("follows:write"), func(c *gin.Context) {
+		userID, _ := c.Get("user_id")
+		if userID == nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "login required"})
+			return
 		}
-		if err := c.BindJSON(&req); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		_, err := userClient.Unfollow(context.Background(), &userProto.UnfollowRequest{
+			FollowerId: userID.(string),
+			FolloweeId: c.Param("id"),
+		})
+		if err != nil {
+			rpcError(c, err)
 			return
 		}
+		c.JSON(http.StatusOK, gin.H{"message": "unfollowed"})
+	})
+
+	router.POST("/tags/:tag/follow", requireScope("follows:write"), func(c *gin.Context) {
 		userID, _ := c.Get("user_id")
-		userName, _ := c.Get("user_name")
-		if userID == nil || userName == nil {
+		if userID == nil {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "login required"})
 			return
 		}
-		resp, err := postClient.Create(context.Background(), &postProto.CreateRequest{
-			Title:      req.Title,
-			Content:    req.Content,
-			AuthorId:   userID.(string),
-			AuthorName: userName.(string),
+		_, err := userClient.FollowTag(context.Background(), &userProto.FollowTagRequest{
+			UserId: userID.(string),
+			Tag:    c.Param("tag"),
 		})
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			rpcError(c, err)
 			return
 		}
-		c.JSON(http.StatusCreated, resp)
+		c.JSON(http.StatusOK, gin.H{"message": "following"})
 	})
 
-	// === Comments endpoints ===
-	router.GET("/comments", func(c *gin.Context) {
-		postID := c.Query("post_id")
-		resp, err := commentClient.List(context.Background(), &commentProto.ListRequest{
-			PostId: postID,
+	router.DELETE("/tags/:tag/follow", requireScope("follows:write"), func(c *gin.Context) {
+		userID, _ := c.Get("user_id")
+		if userID == nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "login required"})
+			return
+		}
+		_, err := userClient.UnfollowTag(context.Background(), &userProto.UnfollowTagRequest{
+			UserId: userID.(string),
+			Tag:    c.Param("tag"),
 		})
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			rpcError(c, err)
 			return
 		}
-		c.JSON(http.StatusOK, resp)
+		c.JSON(http.StatusOK, gin.H{"message": "unfollowed"})
 	})
 
-	router.POST("/comments", func(c *gin.Context) {
-		var req struct {
-			Content string `json:"content"`
-			PostId  string `json:"post_id"`
+	// Users and tags the current user follows
+	router.GET("/users/me/following", func(c *gin.Context) {
+		userID, _ := c.Get("user_id")
+		if userID == nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "login required"})
+			return
 		}
-		if err := c.BindJSON(&req); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		resp, err := userClient.ListFollowing(context.Background(), &userProto.ListFollowingRequest{UserId: userID.(string)})
+		if err != nil {
+			rpcError(c, err)
 			return
 		}
+		c.JSON(http.StatusOK, gin.H{"user_ids": resp.UserIds, "tags": resp.Tags})
+	})
+
+	// Posts by followed users or with followed tags, newest first. Pass
+	// next_cursor back as cursor for the next page.
+	router.GET("/feed", func(c *gin.Context) {
 		userID, _ := c.Get("user_id")
-		userName, _ := c.Get("user_name")
-		if userID == nil || userName == nil {
+		if userID == nil {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "login required"})
 			return
 		}
-		resp, err := commentClient.Create(context.Background(), &commentProto.CreateRequest{
-			Content:    req.Content,
-			AuthorId:   userID.(string),
-			AuthorName: userName.(string),
-			PostId:     req.PostId,
+		following, err := userClient.ListFollowing(context.Background(), &userProto.ListFollowingRequest{UserId: userID.(string)})
+		if err != nil {
+			rpcError(c, err)
+			return
+		}
+		var exclude []string
+		for id := range hiddenAuthors(c, userClient) {
+			exclude = append(exclude, id)
+		}
+		limit, _ := strconv.Atoi(c.Query("limit"))
+		resp, err := postClient.Feed(context.Background(), &postProto.FeedRequest{
+			AuthorIds:        following.UserIds,
+			Tags:             following.Tags,
+			Cursor:           c.Query("cursor"),
+			Limit:            int32(limit),
+			ExcludeAuthorIds: exclude,
 		})
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			rpcError(c, err)
 			return
 		}
-		c.JSON(http.StatusCreated, resp)
+		c.JSON(http.StatusOK, gin.H{"posts": resp.Posts, "next_cursor": resp.NextCursor})
 	})
 
-	// === Users endpoints ===
-	router.GET("/users", func(c *gin.Context) {
-		resp, err := userClient.List(context.Background(), &userProto.ListRequest{
-			Page:  1,
-			Limit: 10,
-		})
+	// === Blocks and mutes ===
+	// Blocked users can't comment on your posts or follow you; blocked and
+	// muted users' posts and comments are hidden from your listings
+	router.POST("/users/:id/block", sessionOnly, func(c *gin.Context) {
+		userID, _ := c.Get("user_id")
+		if userID == nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "login required"})
+			return
+		}
+		_, err := userClient.Block(context.Background(), &userProto.BlockRequest{UserId: userID.(string), TargetId: c.Param("id")})
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			rpcError(c, err)
 			return
 		}
-		c.JSON(http.StatusOK, resp)
+		c.JSON(http.StatusOK, gin.H{"message": "blocked"})
 	})
 
-	router.GET("/users/:id", func(c *gin.Context) {
-		id := c.Param("id")
-		resp, err := userClient.Read(context.Background(), &userProto.ReadRequest{
-			Id: id,
-		})
+	router.DELETE("/users/:id/block", sessionOnly, func(c *gin.Context) {
+		userID, _ := c.Get("user_id")
+		if userID == nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "login required"})
+			return
+		}
+		_, err := userClient.Unblock(context.Background(), &userProto.UnblockRequest{UserId: userID.(string), TargetId: c.Param("id")})
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			rpcError(c, err)
 			return
 		}
-		c.JSON(http.StatusOK, resp)
+		c.JSON(http.StatusOK, gin.H{"message": "unblocked"})
 	})
 
-	router.POST("/users", func(c *gin.Context) {
-		var req struct {
-			Name  string `json:"name"`
-			Email string `json:"email"`
+	router.POST("/users/:id/mute", sessionOnly, func(c *gin.Context) {
+		userID, _ := c.Get("user_id")
+		if userID == nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "login required"})
+			return
+		}
+		_, err := userClient.Mute(context.Background(), &userProto.MuteRequest{UserId: userID.(string), TargetId: c.Param("id")})
+		if err != nil {
+			rpcError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"message": "muted"})
+	})
+
+	router.DELETE("/users/:id/mute", sessionOnly, func(c *gin.Context) {
+		userID, _ := c.Get("user_id")
+		if userID == nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "login required"})
+			return
+		}
+		_, err := userClient.Unmute(context.Background(), &userProto.UnmuteRequest{UserId: userID.(string), TargetId: c.Param("id")})
+		if err != nil {
+			rpcError(c, err)
+			return
 		}
+		c.JSON(http.StatusOK, gin.H{"message": "unmuted"})
+	})
 
-		if err := c.BindJSON(&req); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	router.GET("/users/me/blocks", sessionOnly, func(c *gin.Context) {
+		userID, _ := c.Get("user_id")
+		if userID == nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "login required"})
+			return
+		}
+		resp, err := userClient.ListBlocks(context.Background(), &userProto.ListBlocksRequest{UserId: userID.(string)})
+		if err != nil {
+			rpcError(c, err)
 			return
 		}
+		c.JSON(http.StatusOK, gin.H{"blocked_ids": resp.BlockedIds, "muted_ids": resp.MutedIds})
+	})
 
-		resp, err := userClient.Create(context.Background(), &userProto.CreateRequest{
-			Name:  req.Name,
-			Email: req.Email,
-		})
+	// === Personal data export ===
+	// Everything held about the current user as a zip of JSON and Markdown
+	router.GET("/users/me/export", sessionOnly, func(c *gin.Context) {
+		userID, _ := c.Get("user_id")
+		if userID == nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "login required"})
+			return
+		}
+		e, err := gatherExport(clientContext(c), userID.(string), userClient, postClient, commentClient, sessionStore)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			rpcError(c, err)
 			return
 		}
-		c.JSON(http.StatusCreated, resp)
+		now := time.Now()
+		filename := fmt.Sprintf("blog-export-%s-%s.zip", e.User.Handle, now.UTC().Format("20060102"))
+		c.Header("Content-Type", "application/zip")
+		c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+		c.Header("Cache-Control", "no-store")
+		c.Status(http.StatusOK)
+		if err := e.writeZip(c.Writer, now); err != nil {
+			log.Printf("Failed to write export for %s: %v", userID, err)
+		}
 	})
 
-	// Signup endpoint
-	router.POST("/signup", func(c *gin.Context) {
-		var req struct {
-			Name     string `json:"name"`
-			Email    string `json:"email"`
-			Password string `json:"password"`
+	// === Two-factor authentication ===
+	// Start enrolling: returns the secret and an otpauth:// URI
+	router.POST("/users/me/2fa", sessionOnly, func(c *gin.Context) {
+		userID, _ := c.Get("user_id")
+		if userID == nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "login required"})
+			return
+		}
+		resp, err := userClient.EnrollTOTP(context.Background(), &userProto.EnrollTOTPRequest{UserId: userID.(string)})
+		if err != nil {
+			rpcError(c, err)
+			return
 		}
+		c.JSON(http.StatusOK, resp)
+	})
 
+	// Finish enrolling with a code from the app. Returns the recovery codes.
+	router.POST("/users/me/2fa/confirm", sessionOnly, func(c *gin.Context) {
+		var req struct {
+			Code string `json:"code"`
+		}
 		if err := c.BindJSON(&req); err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
-		if req.Name == "" || req.Email == "" || req.Password == "" {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "all fields required"})
+		userID, _ := c.Get("user_id")
+		if userID == nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "login required"})
 			return
 		}
-		pwHash, err := hashPassword(req.Password)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to hash password"})
+		accountKey, ipKey := lockout.Key("account", userID.(string)), lockout.Key("ip", c.ClientIP())
+		if throttled(c, limiter, accountKey, ipKey) {
 			return
 		}
-		resp, err := userClient.Create(context.Background(), &userProto.CreateRequest{
-			Name:     req.Name,
-			Email:    req.Email,
-			Password: pwHash,
+		resp, err := userClient.ConfirmTOTP(clientContext(c), &userProto.ConfirmTOTPRequest{
+			UserId: userID.(string),
+			Code:   req.Code,
 		})
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			settle(limiter, err, accountKey, ipKey)
+			rpcError(c, err)
 			return
 		}
-		sess := sessions.Default(c)
-		sess.Set("user_id", resp.User.Id)
-		sess.Set("user_name", resp.User.Name)
-		sess.Save()
-		c.JSON(http.StatusCreated, gin.H{"user": resp.User})
+		settle(limiter, nil, accountKey, ipKey)
+		c.JSON(http.StatusOK, resp)
 	})
 
-	// Login endpoint
-	router.POST("/login", func(c *gin.Context) {
+	// Turn 2FA off with a current code or a recovery code
+	router.DELETE("/users/me/2fa", sessionOnly, func(c *gin.Context) {
 		var req struct {
-			Email    string `json:"email"`
-			Password string `json:"password"`
+			Code string `json:"code"`
 		}
 		if err := c.BindJSON(&req); err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
-		// Find user by email
-		usersResp, err := userClient.List(context.Background(), &userProto.ListRequest{Page: 1, Limit: 1000})
+		userID, _ := c.Get("user_id")
+		if userID == nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "login required"})
+			return
+		}
+		accountKey, ipKey := lockout.Key("account", userID.(string)), lockout.Key("ip", c.ClientIP())
+		if throttled(c, limiter, accountKey, ipKey) {
+			return
+		}
+		if _, err := userClient.DisableTOTP(clientContext(c), &userProto.DisableTOTPRequest{
+			UserId: userID.(string),
+			Code:   req.Code,
+		}); err != nil {
+			settle(limiter, err, accountKey, ipKey)
+			rpcError(c, err)
+			return
+		}
+		settle(limiter, nil, accountKey, ipKey)
+		c.JSON(http.StatusOK, gin.H{"message": "two-factor authentication disabled"})
+	})
+
+	// === Sessions ===
+	// List the current user's active sessions
+	router.GET("/users/me/sessions", sessionOnly, func(c *gin.Context) {
+		userID, _ := c.Get("user_id")
+		if userID == nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "login required"})
+			return
+		}
+		infos, err := sessionStore.List(userID.(string))
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
-		var found *userProto.User
+		current := sessions.Default(c).ID()
+		list := make([]gin.H, 0, len(infos))
+		for _, info := range infos {
+			list = append(list, gin.H{
+				"id":           info.ID,
+				"created_at":   info.CreatedAt,
+				"last_seen_at": info.LastSeenAt,
+				"expires_at":   info.ExpiresAt,
+				"ip":           info.IP,
+				"user_agent":   info.UserAgent,
+				"current":      info.ID == current,
+			})
+		}
+		c.JSON(http.StatusOK, gin.H{"sessions": list})
+	})
 
-		for _, u := range usersResp.Users {
-			if u.Email == req.Email {
-				found = u
-				break
-			}
+	// Revoke one session
+	router.DELETE("/users/me/sessions/:id", sessionOnly, func(c *gin.Context) {
+		userID, _ := c.Get("user_id")
+		if userID == nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "login required"})
+			return
 		}
-		if found == nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid credentials"})
+		if !sessionStore.Revoke(userID.(string), c.Param("id")) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "session not found"})
 			return
 		}
-		// Check password
-		rec, err := userClient.Read(context.Background(), &userProto.ReadRequest{Id: found.Id})
-		if err != nil || rec.User == nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid credentials"})
+		audit(c, &auditProto.Event{Action: "session.revoke", TargetType: "user", TargetId: userID.(string)})
+		c.JSON(http.StatusOK, gin.H{"message": "session revoked"})
+	})
+
+	// Revoke every session except the current one
+	router.DELETE("/users/me/sessions", sessionOnly, func(c *gin.Context) {
+		userID, _ := c.Get("user_id")
+		if userID == nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "login required"})
 			return
 		}
-		if !checkPasswordHash(req.Password, rec.User.Password) {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid credentials"})
+		if err := sessionStore.RevokeAll(userID.(string), sessions.Default(c).ID()); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
-		sess := sessions.Default(c)
-		sess.Set("user_id", found.Id)
-		sess.Set("user_name", found.Name)
-		sess.Save()
-		c.JSON(http.StatusOK, gin.H{"user": found})
+		audit(c, &auditProto.Event{Action: "session.revoke_all", TargetType: "user", TargetId: userID.(string)})
+		c.JSON(http.StatusOK, gin.H{"message": "other sessions revoked"})
 	})
 
-	// Logout endpoint
-	router.POST("/logout", func(c *gin.Context) {
-		sess := sessions.Default(c)
-		sess.Clear()
-		sess.Save()
-		c.JSON(http.StatusOK, gin.H{"message": "logged out"})
+	// === Admin endpoints ===
+	// Failed attempt counters and lockouts
+	router.GET("/admin/lockouts", requirePermission(userClient, "users:admin"), func(c *gin.Context) {
+		entries, err := limiter.List()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"lockouts": entries})
 	})
 
-	// Session info endpoint for frontend
-	router.GET("/users/me", func(c *gin.Context) {
-		userID, _ := c.Get("user_id")
-		userName, _ := c.Get("user_name")
-		if userID == nil || userName == nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"user": nil})
+	// Clear a lockout, e.g. "account:jane@example.com"
+	router.DELETE("/admin/lockouts/:key", requirePermission(userClient, "users:admin"), func(c *gin.Context) {
+		if !limiter.Clear(c.Param("key")) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "lockout not found"})
+			return
+		}
+		audit(c, &auditProto.Event{Action: "lockout.clear", TargetType: "lockout", TargetId: c.Param("key")})
+		c.JSON(http.StatusOK, gin.H{"message": "lockout cleared"})
+	})
+
+	// The audit log, newest first. Filters are exact, except that an
+	// action ending in "." matches every action under it, e.g. "auth.".
+	router.GET("/admin/audit", requirePermission(userClient, "users:admin"), func(c *gin.Context) {
+		var times [2]int64
+		for i, name := range []string{"since", "until"} {
+			if v := c.Query(name); v != "" {
+				n, err := strconv.ParseInt(v, 10, 64)
+				if err != nil {
+					c.JSON(http.StatusBadRequest, gin.H{"error": name + " must be a unix time"})
+					return
+				}
+				times[i] = n
+			}
+		}
+		limit, _ := strconv.Atoi(c.Query("limit"))
+		resp, err := auditClient.List(context.Background(), &auditProto.ListRequest{
+			ActorId:    c.Query("actor_id"),
+			Action:     c.Query("action"),
+			TargetType: c.Query("target_type"),
+			TargetId:   c.Query("target_id"),
+			Since:      times[0],
+			Until:      times[1],
+			Limit:      int32(limit),
+			Cursor:     c.Query("cursor"),
+		})
+		if err != nil {
+			rpcError(c, err)
 			return
 		}
-		c.JSON(http.StatusOK, gin.H{"user": gin.H{"id": userID, "name": userName}})
+		c.JSON(http.StatusOK, gin.H{"events": resp.Events, "next_cursor": resp.NextCursor})
 	})
 
 	// === Tags endpoints ===
+	// canTag allows authors to tag their own posts and tag managers any post
+	canTag := func(c *gin.Context, postID string) bool {
+		// Read as the caller, so authors see their own drafts
+		ctx, err := callerContext(c, userClient)
+		if err != nil {
+			rpcError(c, err)
+			return false
+		}
+		resp, err := postClient.Read(ctx, &postProto.ReadRequest{Id: postID})
+		if err != nil {
+			rpcError(c, err)
+			return false
+		}
+		if resp.Post == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "post not found"})
+			return false
+		}
+		userID, _ := c.Get("user_id")
+		if resp.Post.AuthorId != userID && !hasPermission(c, userClient, "tags:manage") {
+			c.JSON(http.StatusForbidden, gin.H{"error": "permission required: tags:manage"})
+			return false
+		}
+		return true
+	}
+
 	// Add tag to post
-	router.POST("/posts/:id/tags", func(c *gin.Context) {
+	router.POST("/posts/:id/tags", requireScope("tags:write"), requirePermission(userClient, "tags:write"), func(c *gin.Context) {
 		postID := c.Param("id")
 		var req struct {
 			Tag string `json:"tag"`
@@ -309,10 +1679,7 @@ func main() {
 			return
 		}
 
-		// Authenticate user
-		_, exists := c.Get("user_id")
-		if !exists {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "login required"})
+		if !canTag(c, postID) {
 			return
 		}
 
@@ -328,14 +1695,11 @@ func main() {
 	})
 
 	// Remove tag from post
-	router.DELETE("/posts/:id/tags/:tag", func(c *gin.Context) {
+	router.DELETE("/posts/:id/tags/:tag", requireScope("tags:write"), requirePermission(userClient, "tags:write"), func(c *gin.Context) {
 		postID := c.Param("id")
 		tag := c.Param("tag")
 
-		// Authenticate user
-		_, exists := c.Get("user_id")
-		if !exists {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "login required"})
+		if !canTag(c, postID) {
 			return
 		}
 
@@ -354,7 +1718,7 @@ func main() {
 	router.GET("/tags", func(c *gin.Context) {
 		postID := c.Query("post_id")
 
-		resp, err := postClient.ListTags(context.Background(), &postProto.ListTagsRequest{
+		resp, err := postClient.ListTags(clientContext(c), &postProto.ListTagsRequest{
 			PostId: postID,
 		})
 		if err != nil {
@@ -369,7 +1733,7 @@ func main() {
 		tag := c.Param("tag")
 
 		// First get all posts
-		resp, err := postClient.List(context.Background(), &postProto.ListRequest{})
+		posts, err := allPosts(clientContext(c), postClient, &postProto.ListRequest{})
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
@@ -377,7 +1741,7 @@ func main() {
 
 		// Filter posts with the requested tag
 		var taggedPosts []*postProto.Post
-		for _, post := range resp.Posts {
+		for _, post := range visiblePosts(posts, hiddenAuthors(c, userClient)) {
 			for _, postTag := range post.Tags {
 				if postTag == tag {
 					taggedPosts = append(taggedPosts, post)
@@ -408,6 +1772,12 @@ func main() {
 	router.GET("/signup.html", func(c *gin.Context) {
 		c.File("./static/signup.html")
 	})
+	router.GET("/forgot.html", func(c *gin.Context) {
+		c.File("./static/forgot.html")
+	})
+	router.GET("/reset.html", func(c *gin.Context) {
+		c.File("./static/reset.html")
+	})
 
 	// Serve user profile page at /@:username
 	router.GET("/@:username", func(c *gin.Context) {
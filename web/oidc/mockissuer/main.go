@@ -0,0 +1,33 @@
+// Command mockissuer is a minimal OpenID Connect provider for trying the
+// blog's external login locally. It serves web/oidc/oidctest, which accepts
+// any email on its login form (or in login_hint). Never run it anywhere real.
+//
+//	go run ./web/oidc/mockissuer
+//	OIDC_PROVIDERS=mock OIDC_MOCK_ISSUER=http://localhost:9400 \
+//	  OIDC_MOCK_CLIENT_ID=blog OIDC_MOCK_CLIENT_SECRET=secret make run-web
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+
+	"github.com/micro/blog/web/oidc/oidctest"
+)
+
+var (
+	addr         = flag.String("addr", ":9400", "listen address")
+	issuer       = flag.String("issuer", "http://localhost:9400", "issuer URL")
+	clientID     = flag.String("client-id", "blog", "accepted client ID")
+	clientSecret = flag.String("client-secret", "secret", "accepted client secret")
+)
+
+func main() {
+	flag.Parse()
+	iss, err := oidctest.New(*issuer, *clientID, *clientSecret)
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("Mock OIDC issuer %s listening on %s", *issuer, *addr)
+	log.Fatal(http.ListenAndServe(*addr, iss))
+}
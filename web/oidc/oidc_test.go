@@ -0,0 +1,191 @@
+package oidc
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/micro/blog/web/oidc/oidctest"
+)
+
+const testRedirect = "http://blog.test/auth/mock/callback"
+
+// newTestProvider returns a provider registered with a mock issuer
+func newTestProvider(t *testing.T) (*Provider, *oidctest.Issuer) {
+	t.Helper()
+	iss, err := oidctest.New("", "blog", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(iss)
+	t.Cleanup(srv.Close)
+	iss.URL = srv.URL
+	p := NewProvider(Config{
+		Name:         "mock",
+		Issuer:       srv.URL,
+		ClientID:     "blog",
+		ClientSecret: "secret",
+		RedirectURL:  testRedirect,
+	})
+	return p, iss
+}
+
+// authorize logs in at the issuer as email and returns the authorization
+// code it redirects back with
+func authorize(t *testing.T, p *Provider, email, state, nonce, verifier string) string {
+	t.Helper()
+	u, err := p.AuthURL(context.Background(), state, nonce, verifier)
+	if err != nil {
+		t.Fatalf("AuthURL: %v", err)
+	}
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	resp, err := client.Get(u + "&login_hint=" + url.QueryEscape(email))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	loc, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil || resp.StatusCode != http.StatusFound {
+		t.Fatalf("authorize: %s, location %q", resp.Status, resp.Header.Get("Location"))
+	}
+	if !strings.HasPrefix(loc.String(), testRedirect+"?") {
+		t.Fatalf("authorize redirected to %s, want %s", loc, testRedirect)
+	}
+	if got := loc.Query().Get("state"); got != state {
+		t.Errorf("state = %q, want %q", got, state)
+	}
+	return loc.Query().Get("code")
+}
+
+func TestExchange(t *testing.T) {
+	p, _ := newTestProvider(t)
+	nonce, verifier := RandomString(), RandomString()
+	code := authorize(t, p, "Jane@example.com", RandomString(), nonce, verifier)
+
+	claims, err := p.Exchange(context.Background(), code, verifier, nonce)
+	if err != nil {
+		t.Fatalf("Exchange: %v", err)
+	}
+	if claims.Issuer != p.Issuer || claims.Subject != "mock-jane@example.com" || claims.Email != "Jane@example.com" || !claims.EmailVerified {
+		t.Errorf("unexpected claims %+v", claims)
+	}
+
+	// Codes are single use
+	if _, err := p.Exchange(context.Background(), code, verifier, nonce); err == nil {
+		t.Error("Exchange redeemed a code twice")
+	}
+}
+
+func TestExchangePKCE(t *testing.T) {
+	p, _ := newTestProvider(t)
+	nonce := RandomString()
+	code := authorize(t, p, "jane@example.com", RandomString(), nonce, RandomString())
+
+	// Without the verifier the challenge was made from, a stolen code is useless
+	if _, err := p.Exchange(context.Background(), code, RandomString(), nonce); err == nil {
+		t.Error("Exchange accepted the wrong PKCE verifier")
+	}
+}
+
+func TestExchangeNonceMismatch(t *testing.T) {
+	p, _ := newTestProvider(t)
+	verifier := RandomString()
+	code := authorize(t, p, "jane@example.com", RandomString(), RandomString(), verifier)
+
+	_, err := p.Exchange(context.Background(), code, verifier, RandomString())
+	if err == nil || !strings.Contains(err.Error(), "nonce") {
+		t.Errorf("Exchange with another nonce = %v, want a nonce error", err)
+	}
+}
+
+func TestExchangeRejectsClaims(t *testing.T) {
+	tests := []struct {
+		name   string
+		claims func(map[string]any)
+		want   string
+	}{
+		{"wrong audience", func(c map[string]any) { c["aud"] = "other-client" }, "not issued for this client"},
+		{"audience list without client", func(c map[string]any) { c["aud"] = []string{"a", "b"} }, "not issued for this client"},
+		{"authorized for another client", func(c map[string]any) {
+			c["aud"] = []string{"blog", "other-client"}
+			c["azp"] = "other-client"
+		}, "authorized for another client"},
+		{"wrong issuer", func(c map[string]any) { c["iss"] = "https://evil.example.com" }, "issuer"},
+		{"expired", func(c map[string]any) { c["exp"] = time.Now().Add(-2 * clockSkew).Unix() }, "expired"},
+		{"no expiry", func(c map[string]any) { delete(c, "exp") }, "expired"},
+		{"not valid yet", func(c map[string]any) { c["nbf"] = time.Now().Add(2 * clockSkew).Unix() }, "not valid yet"},
+		{"no subject", func(c map[string]any) { c["sub"] = "" }, "subject"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, iss := newTestProvider(t)
+			iss.Claims = tt.claims
+			nonce, verifier := RandomString(), RandomString()
+			code := authorize(t, p, "jane@example.com", RandomString(), nonce, verifier)
+
+			_, err := p.Exchange(context.Background(), code, verifier, nonce)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Exchange = %v, want an error containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestExchangeToleratesClockSkew(t *testing.T) {
+	p, iss := newTestProvider(t)
+	iss.Claims = func(c map[string]any) { c["exp"] = time.Now().Add(-clockSkew / 2).Unix() }
+	nonce, verifier := RandomString(), RandomString()
+	code := authorize(t, p, "jane@example.com", RandomString(), nonce, verifier)
+
+	if _, err := p.Exchange(context.Background(), code, verifier, nonce); err != nil {
+		t.Errorf("Exchange of a token just past expiry: %v", err)
+	}
+}
+
+func TestVerifyTamperedToken(t *testing.T) {
+	p, _ := newTestProvider(t)
+	nonce, verifier := RandomString(), RandomString()
+	code := authorize(t, p, "jane@example.com", RandomString(), nonce, verifier)
+	// Redeem the code by hand to get at the raw token
+	m, err := p.discover(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	form := url.Values{"grant_type": {"authorization_code"}, "code": {code}, "redirect_uri": {testRedirect}, "code_verifier": {verifier}}
+	req, _ := http.NewRequest(http.MethodPost, m.TokenEndpoint, strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth("blog", "secret")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var tok struct {
+		IDToken string `json:"id_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tok); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.Verify(context.Background(), tok.IDToken, nonce); err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+
+	// Swap in claims for another user under the same signature
+	parts := strings.Split(tok.IDToken, ".")
+	forged, _ := json.Marshal(map[string]any{
+		"iss": p.Issuer, "aud": "blog", "sub": "mock-admin@example.com",
+		"exp": time.Now().Add(time.Minute).Unix(), "nonce": nonce,
+	})
+	parts[1] = base64.RawURLEncoding.EncodeToString(forged)
+	if _, err := p.Verify(context.Background(), strings.Join(parts, "."), nonce); err == nil {
+		t.Error("Verify accepted a token with altered claims")
+	}
+}
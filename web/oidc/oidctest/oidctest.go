@@ -0,0 +1,205 @@
+// Package oidctest is a minimal OpenID Connect provider for trying and
+// testing the blog's external login. It accepts any email on its login
+// form (or in login_hint) and issues an RS256 signed ID token for it. Never
+// run it anywhere real.
+package oidctest
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"html/template"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Issuer is an http.Handler serving discovery, keys, the authorization
+// endpoint and the token endpoint
+type Issuer struct {
+	// URL is the issuer URL, which the endpoints in discovery are under
+	URL          string
+	ClientID     string
+	ClientSecret string
+	// Claims, if set, can change the claims of each ID token before it is
+	// signed, e.g. to issue an expired token
+	Claims func(claims map[string]any)
+
+	key *rsa.PrivateKey
+	mux *http.ServeMux
+
+	mu     sync.Mutex
+	grants map[string]*grant
+}
+
+// grant is an issued authorization code waiting to be redeemed
+type grant struct {
+	email, name, nonce, challenge, redirectURI string
+	expires                                    time.Time
+}
+
+var loginPage = template.Must(template.New("login").Parse(`<!DOCTYPE html>
+<html><body>
+<h2>Mock issuer</h2>
+<form method="post">
+  {{range $k, $v := .}}<input type="hidden" name="{{$k}}" value="{{index $v 0}}">{{end}}
+  <input name="email" placeholder="Email" required>
+  <input name="name" placeholder="Name">
+  <button>Log in</button>
+</form>
+</body></html>`))
+
+// New returns an issuer with a fresh signing key. URL can be set later,
+// before the first request, when it isn't known until the server starts.
+func New(issuerURL, clientID, clientSecret string) (*Issuer, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+	i := &Issuer{
+		URL:          issuerURL,
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		key:          key,
+		mux:          http.NewServeMux(),
+		grants:       map[string]*grant{},
+	}
+	i.mux.HandleFunc("/.well-known/openid-configuration", i.discovery)
+	i.mux.HandleFunc("/jwks", i.jwks)
+	i.mux.HandleFunc("/authorize", i.authorize)
+	i.mux.HandleFunc("/token", i.token)
+	return i, nil
+}
+
+func (i *Issuer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	i.mux.ServeHTTP(w, r)
+}
+
+func b64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func (i *Issuer) discovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{
+		"issuer":                                i.URL,
+		"authorization_endpoint":                i.URL + "/authorize",
+		"token_endpoint":                        i.URL + "/token",
+		"jwks_uri":                              i.URL + "/jwks",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"code_challenge_methods_supported":      []string{"S256"},
+	})
+}
+
+func (i *Issuer) jwks(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"use": "sig",
+			"alg": "RS256",
+			"kid": "mock",
+			"n":   b64(i.key.N.Bytes()),
+			"e":   b64(big.NewInt(int64(i.key.E)).Bytes()),
+		}},
+	})
+}
+
+func (i *Issuer) authorize(w http.ResponseWriter, r *http.Request) {
+	r.ParseForm()
+	email := r.Form.Get("email")
+	if email == "" {
+		email = r.Form.Get("login_hint")
+	}
+	if email == "" {
+		loginPage.Execute(w, r.URL.Query())
+		return
+	}
+	if r.Form.Get("client_id") != i.ClientID || r.Form.Get("code_challenge_method") != "S256" {
+		http.Error(w, "bad client_id or code_challenge_method", http.StatusBadRequest)
+		return
+	}
+	code := b64(randomBytes())
+	i.mu.Lock()
+	i.grants[code] = &grant{
+		email:       email,
+		name:        r.Form.Get("name"),
+		nonce:       r.Form.Get("nonce"),
+		challenge:   r.Form.Get("code_challenge"),
+		redirectURI: r.Form.Get("redirect_uri"),
+		expires:     time.Now().Add(time.Minute),
+	}
+	i.mu.Unlock()
+	q := url.Values{"code": {code}, "state": {r.Form.Get("state")}}
+	http.Redirect(w, r, r.Form.Get("redirect_uri")+"?"+q.Encode(), http.StatusFound)
+}
+
+func (i *Issuer) token(w http.ResponseWriter, r *http.Request) {
+	r.ParseForm()
+	id, secret, _ := r.BasicAuth()
+	id, _ = url.QueryUnescape(id)
+	secret, _ = url.QueryUnescape(secret)
+	if id != i.ClientID || secret != i.ClientSecret {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+	i.mu.Lock()
+	g := i.grants[r.Form.Get("code")]
+	delete(i.grants, r.Form.Get("code"))
+	i.mu.Unlock()
+	sum := sha256.Sum256([]byte(r.Form.Get("code_verifier")))
+	if g == nil || time.Now().After(g.expires) || g.redirectURI != r.Form.Get("redirect_uri") || b64(sum[:]) != g.challenge {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	now := time.Now()
+	claims := map[string]any{
+		"iss":                i.URL,
+		"sub":                "mock-" + strings.ToLower(g.email),
+		"aud":                i.ClientID,
+		"iat":                now.Unix(),
+		"exp":                now.Add(5 * time.Minute).Unix(),
+		"nonce":              g.nonce,
+		"email":              g.email,
+		"email_verified":     true,
+		"name":               g.name,
+		"preferred_username": strings.Split(g.email, "@")[0],
+	}
+	if i.Claims != nil {
+		i.Claims(claims)
+	}
+	payload, _ := json.Marshal(claims)
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "kid": "mock", "typ": "JWT"})
+	signed := b64(header) + "." + b64(payload)
+	digest := sha256.Sum256([]byte(signed))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, i.key, crypto.SHA256, digest[:])
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "server_error"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"access_token": b64(randomBytes()),
+		"token_type":   "Bearer",
+		"expires_in":   300,
+		"id_token":     signed + "." + b64(sig),
+	})
+}
+
+func randomBytes() []byte {
+	b := make([]byte, 24)
+	rand.Read(b)
+	return b
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"context"
+
+	postProto "github.com/micro/blog/posts/proto"
+)
+
+// postsPageSize is the most posts Posts.List returns at once
+const postsPageSize = 100
+
+// allPosts follows Posts.List's cursors to collect every post matching req
+func allPosts(ctx context.Context, posts postProto.PostsService, req *postProto.ListRequest) ([]*postProto.Post, error) {
+	req.Limit = postsPageSize
+	req.Cursor = ""
+	var all []*postProto.Post
+	for {
+		resp, err := posts.List(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, resp.Posts...)
+		if resp.NextCursor == "" {
+			return all, nil
+		}
+		req.Cursor = resp.NextCursor
+	}
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go-micro.dev/v5/metadata"
+
+	userProto "github.com/micro/blog/users/proto"
+)
+
+// rolePermissions lists what each role may do. Permissions share names with
+// token scopes where they overlap, so a route can require both.
+//
+//   - posts:write      create posts
+//   - comments:write   create comments
+//   - tags:write       tag and untag your own posts
+//   - tags:manage      tag and untag any post
+//   - content:moderate edit or remove other users' posts and comments
+//   - users:admin      create users and assign roles
+var rolePermissions = map[string][]string{
+	"admin": {
+		"posts:write", "comments:write", "tags:write", "tags:manage",
+		"content:moderate", "users:admin",
+	},
+	"editor": {
+		"posts:write", "comments:write", "tags:write", "tags:manage",
+		"content:moderate",
+	},
+	"author":    {"posts:write", "comments:write", "tags:write"},
+	"commenter": {"comments:write"},
+}
+
+// permitted reports whether any of roles grants perm
+func permitted(roles []string, perm string) bool {
+	for _, r := range roles {
+		if slices.Contains(rolePermissions[r], perm) {
+			return true
+		}
+	}
+	return false
+}
+
+// permissionsOf returns the permissions granted by roles
+func permissionsOf(roles []string) []string {
+	var perms []string
+	for _, r := range roles {
+		for _, p := range rolePermissions[r] {
+			if !slices.Contains(perms, p) {
+				perms = append(perms, p)
+			}
+		}
+	}
+	return perms
+}
+
+// currentUser returns the logged in user's record, read from the users
+// service once per request. It returns nil for anonymous requests.
+func currentUser(c *gin.Context, users userProto.UsersService) (*userProto.User, error) {
+	if v, ok := c.Get("user"); ok {
+		return v.(*userProto.User), nil
+	}
+	userID, _ := c.Get("user_id")
+	if userID == nil {
+		return nil, nil
+	}
+	resp, err := users.Read(context.Background(), &userProto.ReadRequest{Id: userID.(string)})
+	if err != nil {
+		return nil, err
+	}
+	c.Set("user", resp.User)
+	return resp.User, nil
+}
+
+// userRoles returns the roles of the current user. They're read fresh for
+// each request rather than kept in the session, so role changes apply
+// immediately.
+func userRoles(c *gin.Context, users userProto.UsersService) ([]string, error) {
+	user, err := currentUser(c, users)
+	if err != nil || user == nil {
+		return nil, err
+	}
+	return user.Roles, nil
+}
+
+// hasPermission reports whether the current user holds perm
+func hasPermission(c *gin.Context, users userProto.UsersService, perm string) bool {
+	roles, err := userRoles(c, users)
+	return err == nil && permitted(roles, perm)
+}
+
+// requirePermission rejects requests from anonymous users and from users
+// whose roles don't grant perm
+func requirePermission(users userProto.UsersService, perm string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := c.Get("user_id"); !ok {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "login required"})
+			return
+		}
+		roles, err := userRoles(c, users)
+		if err != nil {
+			rpcError(c, err)
+			c.Abort()
+			return
+		}
+		if !permitted(roles, perm) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "permission required: " + perm})
+			return
+		}
+		c.Next()
+	}
+}
+
+// callerContext returns a context carrying clientMetadata and the current
+// user's permissions, for services that check who is calling
+func callerContext(c *gin.Context, users userProto.UsersService) (context.Context, error) {
+	md := clientMetadata(c)
+	if _, ok := c.Get("user_id"); !ok {
+		return metadata.NewContext(context.Background(), md), nil
+	}
+	roles, err := userRoles(c, users)
+	if err != nil {
+		return nil, err
+	}
+	md["User-Permissions"] = strings.Join(permissionsOf(roles), ",")
+	return metadata.NewContext(context.Background(), md), nil
+}
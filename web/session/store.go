@@ -0,0 +1,288 @@
+// Package session implements a gin-contrib/sessions store that keeps
+// session data server side in the go-micro store. The cookie only carries
+// a signed session ID, so sessions can be listed, expired and revoked.
+package session
+
+import (
+	"bytes"
+	"encoding/base32"
+	"encoding/gob"
+	"encoding/json"
+	"net"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gorilla/securecookie"
+	gsessions "github.com/gorilla/sessions"
+	"go-micro.dev/v5/store"
+)
+
+// Info describes a session for listing to its owner
+type Info struct {
+	ID         string `json:"id"`
+	UserID     string `json:"user_id"`
+	CreatedAt  int64  `json:"created_at"`
+	LastSeenAt int64  `json:"last_seen_at"`
+	ExpiresAt  int64  `json:"expires_at"`
+	IP         string `json:"ip"`
+	UserAgent  string `json:"user_agent"`
+}
+
+// record is a session as kept in the store
+type record struct {
+	Info
+	// Values is the gob encoded session.Values map
+	Values []byte `json:"values"`
+}
+
+// CSRFTokenKey is the session value holding the gateway's CSRF token. It is
+// dropped when the session ID rotates.
+const CSRFTokenKey = "csrf_token"
+
+// touchInterval limits how often LastSeenAt is written back
+const touchInterval = time.Minute
+
+var encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// Store is a sessions.Store backed by a go-micro store
+type Store struct {
+	store   store.Store
+	codecs  []securecookie.Codec
+	options *gsessions.Options
+	// ttl is the absolute lifetime of a session from creation
+	ttl time.Duration
+	// idle expires sessions that haven't been used for this long
+	idle time.Duration
+}
+
+// NewStore returns a Store that signs session IDs with key. Sessions last
+// at most ttl, and end early after idle without a request.
+func NewStore(st store.Store, key []byte, ttl, idle time.Duration) *Store {
+	codec := securecookie.New(key, nil)
+	codec.MaxAge(int(ttl.Seconds()))
+	return &Store{
+		store:  st,
+		codecs: []securecookie.Codec{codec},
+		options: &gsessions.Options{
+			Path:     "/",
+			MaxAge:   int(ttl.Seconds()),
+			HttpOnly: true,
+			SameSite: http.SameSiteLaxMode,
+		},
+		ttl:  ttl,
+		idle: idle,
+	}
+}
+
+func sessionKey(id string) string {
+	return "session-" + id
+}
+
+// userSessionKey indexes a user's sessions so they can be listed
+func userSessionKey(userID, id string) string {
+	return "usersession-" + userID + "-" + id
+}
+
+// Options sets the cookie options for new sessions
+func (s *Store) Options(opts sessions.Options) {
+	s.options = opts.ToGorillaOptions()
+}
+
+// Get returns the session for the request, cached per request
+func (s *Store) Get(r *http.Request, name string) (*gsessions.Session, error) {
+	return gsessions.GetRegistry(r).Get(s, name)
+}
+
+// New loads the session named by the request cookie. A missing, expired,
+// idle or revoked session yields a new empty one.
+func (s *Store) New(r *http.Request, name string) (*gsessions.Session, error) {
+	sess := gsessions.NewSession(s, name)
+	opts := *s.options
+	sess.Options = &opts
+	sess.IsNew = true
+
+	c, err := r.Cookie(name)
+	if err != nil {
+		return sess, nil
+	}
+	var id string
+	if err := securecookie.DecodeMulti(name, c.Value, &id, s.codecs...); err != nil {
+		// Not an error worth surfacing: start a fresh session
+		return sess, nil
+	}
+	rec := s.read(id)
+	if rec == nil {
+		return sess, nil
+	}
+	now := time.Now()
+	if now.Unix() > rec.ExpiresAt || now.Sub(time.Unix(rec.LastSeenAt, 0)) > s.idle {
+		s.delete(rec)
+		return sess, nil
+	}
+	if err := gob.NewDecoder(bytes.NewReader(rec.Values)).Decode(&sess.Values); err != nil {
+		return sess, nil
+	}
+	sess.ID = id
+	sess.IsNew = false
+
+	if now.Sub(time.Unix(rec.LastSeenAt, 0)) > touchInterval {
+		rec.LastSeenAt = now.Unix()
+		s.write(rec)
+	}
+	return sess, nil
+}
+
+// Save persists the session and sets the cookie. A negative MaxAge deletes it.
+func (s *Store) Save(r *http.Request, w http.ResponseWriter, sess *gsessions.Session) error {
+	var rec *record
+	if sess.ID != "" {
+		rec = s.read(sess.ID)
+	}
+
+	if sess.Options.MaxAge < 0 {
+		if rec != nil {
+			s.delete(rec)
+		}
+		http.SetCookie(w, gsessions.NewCookie(sess.Name(), "", sess.Options))
+		return nil
+	}
+
+	userID, _ := sess.Values["user_id"].(string)
+
+	// Start a new session ID whenever the user changes, so an ID planted
+	// before login can't be used afterwards. The CSRF token goes with it,
+	// and the client fetches a fresh one.
+	if rec != nil && rec.UserID != userID {
+		s.delete(rec)
+		rec = nil
+		delete(sess.Values, CSRFTokenKey)
+	}
+	now := time.Now()
+	if rec == nil {
+		sess.ID = encoding.EncodeToString(securecookie.GenerateRandomKey(32))
+		rec = &record{Info: Info{
+			ID:        sess.ID,
+			CreatedAt: now.Unix(),
+			ExpiresAt: now.Add(s.ttl).Unix(),
+		}}
+	}
+	rec.UserID = userID
+	rec.LastSeenAt = now.Unix()
+	rec.IP = clientIP(r)
+	rec.UserAgent = r.UserAgent()
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(sess.Values); err != nil {
+		return err
+	}
+	rec.Values = buf.Bytes()
+	if err := s.write(rec); err != nil {
+		return err
+	}
+
+	encoded, err := securecookie.EncodeMulti(sess.Name(), sess.ID, s.codecs...)
+	if err != nil {
+		return err
+	}
+	opts := *sess.Options
+	opts.MaxAge = int(time.Until(time.Unix(rec.ExpiresAt, 0)).Seconds())
+	http.SetCookie(w, gsessions.NewCookie(sess.Name(), encoded, &opts))
+	return nil
+}
+
+// List returns the active sessions of a user, most recently used first
+func (s *Store) List(userID string) ([]*Info, error) {
+	recs, err := s.store.Read("usersession-"+userID+"-", store.ReadPrefix())
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	var infos []*Info
+	for _, r := range recs {
+		rec := s.read(string(r.Value))
+		if rec == nil || rec.UserID != userID {
+			_ = s.store.Delete(r.Key)
+			continue
+		}
+		if now.Unix() > rec.ExpiresAt || now.Sub(time.Unix(rec.LastSeenAt, 0)) > s.idle {
+			s.delete(rec)
+			continue
+		}
+		info := rec.Info
+		infos = append(infos, &info)
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].LastSeenAt > infos[j].LastSeenAt
+	})
+	return infos, nil
+}
+
+// Revoke ends one of a user's sessions. It returns false if the user has
+// no session with that ID.
+func (s *Store) Revoke(userID, id string) bool {
+	rec := s.read(id)
+	if rec == nil || rec.UserID != userID {
+		return false
+	}
+	s.delete(rec)
+	return true
+}
+
+// RevokeAll ends every session of a user except the one with ID except,
+// which may be empty.
+func (s *Store) RevokeAll(userID, except string) error {
+	infos, err := s.List(userID)
+	if err != nil {
+		return err
+	}
+	for _, info := range infos {
+		if info.ID != except {
+			s.Revoke(userID, info.ID)
+		}
+	}
+	return nil
+}
+
+func (s *Store) read(id string) *record {
+	recs, err := s.store.Read(sessionKey(id))
+	if err != nil || len(recs) == 0 {
+		return nil
+	}
+	var rec record
+	if err := json.Unmarshal(recs[0].Value, &rec); err != nil {
+		return nil
+	}
+	return &rec
+}
+
+func (s *Store) write(rec *record) error {
+	b, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	expiry := time.Until(time.Unix(rec.ExpiresAt, 0))
+	if err := s.store.Write(&store.Record{Key: sessionKey(rec.ID), Value: b, Expiry: expiry}); err != nil {
+		return err
+	}
+	if rec.UserID != "" {
+		return s.store.Write(&store.Record{Key: userSessionKey(rec.UserID, rec.ID), Value: []byte(rec.ID), Expiry: expiry})
+	}
+	return nil
+}
+
+func (s *Store) delete(rec *record) {
+	_ = s.store.Delete(sessionKey(rec.ID))
+	if rec.UserID != "" {
+		_ = s.store.Delete(userSessionKey(rec.UserID, rec.ID))
+	}
+}
+
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
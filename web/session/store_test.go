@@ -0,0 +1,231 @@
+package session
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	gsessions "github.com/gorilla/sessions"
+	"go-micro.dev/v5/store"
+)
+
+const cookieName = "session"
+
+func newTestStore(t *testing.T) *Store {
+	t.Helper()
+	// The file store the gateway runs with; the memory store's prefix reads
+	// return nothing without a limit
+	st := store.NewFileStore(store.DirOption(t.TempDir()))
+	t.Cleanup(func() { st.Close() })
+	return NewStore(st, []byte("0123456789abcdef0123456789abcdef"), time.Hour, 30*time.Minute)
+}
+
+// load returns the session a request with cookie would get
+func load(t *testing.T, s *Store, cookie *http.Cookie) *gsessions.Session {
+	t.Helper()
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	if cookie != nil {
+		r.AddCookie(cookie)
+	}
+	sess, err := s.New(r, cookieName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sess
+}
+
+// save saves a session and returns the cookie set
+func save(t *testing.T, s *Store, sess *gsessions.Session) *http.Cookie {
+	t.Helper()
+	w := httptest.NewRecorder()
+	if err := s.Save(httptest.NewRequest(http.MethodGet, "/", nil), w, sess); err != nil {
+		t.Fatal(err)
+	}
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 {
+		t.Fatalf("Save set %d cookies, want 1", len(cookies))
+	}
+	return cookies[0]
+}
+
+// login starts a session for a user and returns its cookie
+func login(t *testing.T, s *Store, userID string) *http.Cookie {
+	t.Helper()
+	sess := load(t, s, nil)
+	sess.Values["user_id"] = userID
+	return save(t, s, sess)
+}
+
+func TestSaveAndLoad(t *testing.T) {
+	s := newTestStore(t)
+	sess := load(t, s, nil)
+	if !sess.IsNew {
+		t.Fatal("session without a cookie isn't new")
+	}
+	sess.Values["theme"] = "dark"
+	cookie := save(t, s, sess)
+
+	loaded := load(t, s, cookie)
+	if loaded.IsNew || loaded.ID != sess.ID || loaded.Values["theme"] != "dark" {
+		t.Errorf("loaded session %q %v (new %v), want %q with the saved values", loaded.ID, loaded.Values, loaded.IsNew, sess.ID)
+	}
+
+	// A tampered cookie is ignored
+	forged := *cookie
+	forged.Value = "x" + forged.Value
+	if !load(t, s, &forged).IsNew {
+		t.Error("a tampered cookie loaded a session")
+	}
+}
+
+func TestSaveRotatesIDWhenUserChanges(t *testing.T) {
+	s := newTestStore(t)
+	sess := load(t, s, nil)
+	sess.Values[CSRFTokenKey] = "pre-login-token"
+	sess.Values["theme"] = "dark"
+	anonymous := save(t, s, sess)
+	anonymousID := sess.ID
+
+	// Logging in keeps the values but not the ID or the CSRF token
+	sess = load(t, s, anonymous)
+	sess.Values["user_id"] = "jane"
+	loggedIn := save(t, s, sess)
+	if sess.ID == anonymousID {
+		t.Fatal("session ID kept across login")
+	}
+	if !load(t, s, anonymous).IsNew {
+		t.Error("the pre-login session ID still loads")
+	}
+	sess = load(t, s, loggedIn)
+	if sess.Values["user_id"] != "jane" || sess.Values["theme"] != "dark" {
+		t.Errorf("values after login = %v", sess.Values)
+	}
+	if _, ok := sess.Values[CSRFTokenKey]; ok {
+		t.Error("the pre-login CSRF token survived login")
+	}
+
+	// Saving again as the same user keeps the ID and the token
+	sess.Values[CSRFTokenKey] = "token"
+	save(t, s, sess)
+	if again := load(t, s, loggedIn); again.ID != sess.ID || again.Values[CSRFTokenKey] != "token" {
+		t.Errorf("resaving as the same user gave %q %v", again.ID, again.Values)
+	}
+
+	// Logging out rotates the ID too
+	sess = load(t, s, loggedIn)
+	delete(sess.Values, "user_id")
+	loggedOut := save(t, s, sess)
+	if !load(t, s, loggedIn).IsNew || load(t, s, loggedOut).IsNew {
+		t.Error("session ID kept across logout")
+	}
+}
+
+func TestSaveNegativeMaxAgeDeletes(t *testing.T) {
+	s := newTestStore(t)
+	cookie := login(t, s, "jane")
+	sess := load(t, s, cookie)
+	sess.Options.MaxAge = -1
+	save(t, s, sess)
+	if !load(t, s, cookie).IsNew {
+		t.Error("a deleted session still loads")
+	}
+	if infos, _ := s.List("jane"); len(infos) != 0 {
+		t.Errorf("List after delete = %d sessions", len(infos))
+	}
+}
+
+func TestExpiry(t *testing.T) {
+	tests := []struct {
+		name   string
+		expire func(*record)
+	}{
+		{"absolute", func(r *record) { r.ExpiresAt = time.Now().Add(-time.Second).Unix() }},
+		{"idle", func(r *record) { r.LastSeenAt = time.Now().Add(-31 * time.Minute).Unix() }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestStore(t)
+			cookie := login(t, s, "jane")
+			id := load(t, s, cookie).ID
+
+			// Used just now, the session stays
+			rec := s.read(id)
+			rec.LastSeenAt = time.Now().Add(-29 * time.Minute).Unix()
+			if err := s.write(rec); err != nil {
+				t.Fatal(err)
+			}
+			if load(t, s, cookie).IsNew {
+				t.Fatal("session expired early")
+			}
+
+			rec = s.read(id)
+			tt.expire(rec)
+			if err := s.write(rec); err != nil {
+				t.Fatal(err)
+			}
+			if infos, _ := s.List("jane"); len(infos) != 0 {
+				t.Errorf("List shows %d expired sessions", len(infos))
+			}
+			if !load(t, s, cookie).IsNew {
+				t.Error("an expired session loads")
+			}
+			if s.read(id) != nil {
+				t.Error("an expired session wasn't deleted")
+			}
+		})
+	}
+}
+
+func TestListAndRevoke(t *testing.T) {
+	s := newTestStore(t)
+	var cookies []*http.Cookie
+	var ids []string
+	for i := 0; i < 3; i++ {
+		c := login(t, s, "jane")
+		cookies = append(cookies, c)
+		ids = append(ids, load(t, s, c).ID)
+	}
+	other := login(t, s, "john")
+
+	infos, err := s.List("jane")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(infos) != 3 {
+		t.Fatalf("List = %d sessions, want 3", len(infos))
+	}
+	for _, info := range infos {
+		if info.UserID != "jane" {
+			t.Errorf("List returned a session of %q", info.UserID)
+		}
+	}
+
+	// Users can only revoke their own sessions
+	if s.Revoke("john", ids[0]) {
+		t.Error("revoked another user's session")
+	}
+	if !s.Revoke("jane", ids[0]) || !load(t, s, cookies[0]).IsNew {
+		t.Error("Revoke didn't end the session")
+	}
+
+	// Revoking all but the current session leaves only that one
+	if err := s.RevokeAll("jane", ids[2]); err != nil {
+		t.Fatal(err)
+	}
+	if !load(t, s, cookies[1]).IsNew || load(t, s, cookies[2]).IsNew {
+		t.Error("RevokeAll didn't spare only the current session")
+	}
+	if infos, _ := s.List("jane"); len(infos) != 1 || infos[0].ID != ids[2] {
+		t.Errorf("List after RevokeAll = %v", infos)
+	}
+	if err := s.RevokeAll("jane", ""); err != nil {
+		t.Fatal(err)
+	}
+	if infos, _ := s.List("jane"); len(infos) != 0 {
+		t.Errorf("List after revoking everything = %d sessions", len(infos))
+	}
+	if load(t, s, other).IsNew {
+		t.Error("revoking one user's sessions ended another's")
+	}
+}
@@ -10,6 +10,27 @@ function timeAgo(ts) {
   return d.toLocaleDateString();
 }
 
+// Requests that change state carry the session's CSRF token, fetched once
+// from /csrf. A 403 may mean the session (and its token) changed, so the
+// token is refreshed and the request retried once.
+let csrfToken = null;
+async function getCSRFToken(refresh) {
+  if (!csrfToken || refresh) {
+    const res = await fetch('/csrf');
+    csrfToken = res.ok ? (await res.json()).csrf_token : null;
+  }
+  return csrfToken;
+}
+async function send(url, opts = {}) {
+  const withToken = async (refresh) => fetch(url, {
+    ...opts,
+    headers: { ...(opts.headers || {}), 'X-CSRF-Token': await getCSRFToken(refresh) || '' }
+  });
+  const res = await withToken(false);
+  if (res.status !== 403) return res;
+  return withToken(true);
+}
+
 async function fetchSession() {
   const res = await fetch('/users/me');
   if (!res.ok) return null;
@@ -17,10 +38,11 @@ async function fetchSession() {
 }
 function renderAuthLinks(user) {
   const authDiv = document.getElementById('auth-links');
+  if (!authDiv) return;
   if (user) {
-    authDiv.innerHTML = `<span>Logged in as <b>${user.name}</b></span> <button id='logoutBtn'>Logout</button>`;
+    authDiv.innerHTML = `<span>Logged in as <b>${escapeHtml(user.name || '')}</b></span> <button id='logoutBtn'>Logout</button>`;
     document.getElementById('logoutBtn').onclick = async () => {
-      await fetch('/logout', { method: 'POST' });
+      await send('/logout', { method: 'POST' });
       document.cookie = 'session=; Max-Age=0; path=/;';
       location.reload();
     };
@@ -28,10 +50,12 @@ function renderAuthLinks(user) {
     authDiv.innerHTML = `<a href='/login.html'>Login</a> | <a href='/signup.html'>Sign Up</a>`;
   }
 }
-async function fetchPosts() {
-  const res = await fetch('/posts');
+// Fetch a page of posts, newest first. Pass the previous page's
+// next_cursor to get the page after it.
+async function fetchPosts(cursor) {
+  const res = await fetch(cursor ? `/posts?cursor=${encodeURIComponent(cursor)}` : '/posts');
   const data = await res.json();
-  return data.posts || [];
+  return { posts: data.posts || [], nextCursor: data.next_cursor || '' };
 }
 async function fetchComments(postId) {
   const res = await fetch(`/comments?post_id=${postId}`);
@@ -43,12 +67,26 @@ function linkify(text) {
   if (!text) return '';
   return text.replace(/(https?:\/\/[^\s]+)/g, url => `<a href="${url}" target="_blank" rel="noopener noreferrer">${url}</a>`);
 }
+// Content of a post or comment as HTML. The services render Markdown and
+// sanitize it; anything without rendered HTML is shown as escaped text.
+function contentHtml(item) {
+  if (item.content_html) return item.content_html;
+  return linkify(escapeHtml(item.content || ''));
+}
+function escapeHtml(text) {
+  return text.replace(/[&<>"']/g, ch => ({ '&': '&amp;', '<': '&lt;', '>': '&gt;', '"': '&quot;', "'": '&#39;' })[ch]);
+}
+// Return a URL only if it is http(s), so fetched pages can't supply javascript: or data: URLs
+function httpUrl(url) {
+  return /^https?:\/\//i.test(url || '') ? url : '';
+}
 async function renderFeed() {
   const feed = document.getElementById('feed');
+  if (!feed) return;
   feed.innerHTML = '';
-  let posts = await fetchPosts();
-  // Sort posts by created_at descending (reverse chronological)
-  posts.sort((a, b) => (b.created_at || 0) - (a.created_at || 0));
+  let { posts } = await fetchPosts();
+  // Sort posts by publish time descending (reverse chronological)
+  posts.sort((a, b) => (b.publish_at || b.created_at || 0) - (a.publish_at || a.created_at || 0));
   for (const post of posts) {
     const postDiv = document.createElement('div');
     postDiv.className = 'post';
@@ -56,18 +94,18 @@ async function renderFeed() {
     if (post.link_preview && post.link_preview.url) {
       linkPreviewHtml = `
         <div class="link-preview" style="border:1px solid #ddd;border-radius:6px;padding:0.5em;margin-bottom:0.5em;background:#fff;display:flex;gap:1em;align-items:center;">
-          ${post.link_preview.image ? `<img src="${post.link_preview.image}" alt="preview image" style="max-width:80px;max-height:80px;border-radius:4px;object-fit:cover;">` : ''}
+          ${httpUrl(post.link_preview.image) ? `<img src="${escapeHtml(post.link_preview.image)}" alt="preview image" style="max-width:80px;max-height:80px;border-radius:4px;object-fit:cover;">` : ''}
           <div style="flex:1;min-width:0;">
-            <div style="font-weight:bold;white-space:nowrap;overflow:hidden;text-overflow:ellipsis;">${post.link_preview.title || post.link_preview.url}</div>
-            <div style="color:#555;font-size:0.95em;white-space:nowrap;overflow:hidden;text-overflow:ellipsis;">${post.link_preview.description || ''}</div>
-            <a href="${post.link_preview.url}" target="_blank" style="color:#1a0dab;font-size:0.95em;word-break:break-all;">${post.link_preview.url}</a>
+            <div style="font-weight:bold;white-space:nowrap;overflow:hidden;text-overflow:ellipsis;">${escapeHtml(post.link_preview.title || post.link_preview.url)}</div>
+            <div style="color:#555;font-size:0.95em;white-space:nowrap;overflow:hidden;text-overflow:ellipsis;">${escapeHtml(post.link_preview.description || '')}</div>
+            <a href="${escapeHtml(httpUrl(post.link_preview.url))}" target="_blank" rel="noopener noreferrer" style="color:#1a0dab;font-size:0.95em;word-break:break-all;">${escapeHtml(post.link_preview.url)}</a>
           </div>
         </div>
       `;
     }
     postDiv.innerHTML = `
-      <div class="post-title">${post.title} <span style='font-size:0.9em;color:#888;'>by <a href="/@${encodeURIComponent(post.author_name ? post.author_name : 'unknown')}">${post.author_name ? post.author_name : 'unknown'}</a>${post.created_at ? ' • ' + timeAgo(post.created_at) : ''}</span></div>
-      <div class="post-content">${linkify(post.content)}</div>
+      <div class="post-title">${escapeHtml(post.title || '')} <span style='font-size:0.9em;color:#888;'>by ${authorLink(post)}${post.created_at ? ' • ' + timeAgo(post.created_at) : ''}</span></div>
+      <div class="post-content">${contentHtml(post)}</div>
       ${linkPreviewHtml}
       <div class="comments" id="comments-${post.id}"></div>
       <form class="comment-form" data-post-id="${post.id}">
@@ -81,7 +119,7 @@ async function renderFeed() {
       e.preventDefault();
       const input = e.target.querySelector('input');
       const content = input.value;
-      await fetch('/comments', {
+      await send('/comments', {
         method: 'POST',
         headers: { 'Content-Type': 'application/json' },
         body: JSON.stringify({ content, post_id: post.id })
@@ -94,21 +132,40 @@ async function renderFeed() {
 async function renderComments(postId) {
   const commentsDiv = document.getElementById(`comments-${postId}`);
   const comments = await fetchComments(postId);
-  commentsDiv.innerHTML = comments.map(c => `<div class="comment">${linkify(c.content)} <span style='color:#888;'>by <a href="/@${encodeURIComponent(c.author_name ? c.author_name : 'unknown')}">${c.author_name ? c.author_name : 'unknown'}</a>${c.created_at ? ' • ' + timeAgo(c.created_at) : ''}</span></div>`).join('');
+  commentsDiv.innerHTML = comments.map(c => `<div class="comment">${contentHtml(c)} <span style='color:#888;'>by ${authorLink(c)}${c.created_at ? ' • ' + timeAgo(c.created_at) : ''}</span></div>`).join('');
 }
 
 // == Post Form ==
 const postForm = document.getElementById('postForm');
 if (postForm) {
+  const statusSelect = document.getElementById('status');
+  const publishAtInput = document.getElementById('publishAt');
+  statusSelect.addEventListener('change', () => {
+    publishAtInput.hidden = statusSelect.value !== 'scheduled';
+  });
   postForm.addEventListener('submit', async (e) => {
     e.preventDefault();
     const title = document.getElementById('title').value;
     const content = document.getElementById('content').value;
-    await fetch('/posts', {
+    const status = statusSelect.value;
+    const body = { title, content, status };
+    if (status === 'scheduled') {
+      body.publish_at = Math.floor(new Date(publishAtInput.value).getTime() / 1000) || 0;
+    }
+    const res = await send('/posts', {
       method: 'POST',
       headers: { 'Content-Type': 'application/json' },
-      body: JSON.stringify({ title, content })
+      body: JSON.stringify(body)
     });
+    const errorDiv = document.getElementById('postError');
+    if (!res.ok) {
+      const data = await res.json();
+      errorDiv.textContent = data.error || 'Post failed';
+      return;
+    }
+    errorDiv.textContent = '';
+    statusSelect.value = 'published';
+    publishAtInput.hidden = true;
     document.getElementById('title').value = '';
     document.getElementById('content').value = '';
     renderFeed();
@@ -133,7 +190,7 @@ document.addEventListener('DOMContentLoaded', () => {
       const errorDiv = document.getElementById('signupError');
       errorDiv.textContent = '';
       try {
-        const res = await fetch('/signup', {
+        const res = await send('/signup', {
           method: 'POST',
           headers: { 'Content-Type': 'application/json' },
           body: JSON.stringify({ name, email, password })
@@ -164,16 +221,70 @@ document.addEventListener('DOMContentLoaded', () => {
       console.log('Login form submitted', { email, password });
       errorDiv.textContent = '';
       try {
-        const res = await fetch('/login', {
+        const res = await send('/login', {
           method: 'POST',
           headers: { 'Content-Type': 'application/json' },
           body: JSON.stringify({ email, password })
         });
+        const data = await res.json();
         if (!res.ok) {
-          const data = await res.json();
           errorDiv.textContent = data.error || 'Login failed';
           return;
         }
+        if (data.two_factor_required) {
+          loginForm.style.display = 'none';
+          document.getElementById('twoFactorForm').style.display = '';
+          document.getElementById('twoFactorCode').focus();
+          return;
+        }
+        location.href = '/';
+      } catch (err) {
+        errorDiv.textContent = 'Network error';
+      }
+    });
+  }
+
+  // Errors and pending 2FA from an external provider login
+  const params = new URLSearchParams(location.search);
+  if (loginForm && params.get('error')) {
+    document.getElementById('loginError').textContent = params.get('error');
+  }
+  if (loginForm && params.get('two_factor')) {
+    loginForm.style.display = 'none';
+    document.getElementById('twoFactorForm').style.display = '';
+  }
+
+  const providersDiv = document.getElementById('providers');
+  if (providersDiv) {
+    fetch('/auth/providers').then(res => res.json()).then(data => {
+      (data.providers || []).forEach(p => {
+        const link = document.createElement('a');
+        link.href = `/auth/${encodeURIComponent(p.name)}/login`;
+        link.className = 'provider-login';
+        link.textContent = `Log in with ${p.label}`;
+        providersDiv.appendChild(link);
+      });
+    });
+  }
+
+  const twoFactorForm = document.getElementById('twoFactorForm');
+  if (twoFactorForm) {
+    twoFactorForm.addEventListener('submit', async (e) => {
+      e.preventDefault();
+      const code = document.getElementById('twoFactorCode').value.trim();
+      const errorDiv = document.getElementById('twoFactorError');
+      errorDiv.textContent = '';
+      try {
+        const res = await send('/login/2fa', {
+          method: 'POST',
+          headers: { 'Content-Type': 'application/json' },
+          body: JSON.stringify({ code })
+        });
+        if (!res.ok) {
+          const data = await res.json();
+          errorDiv.textContent = data.error || 'Verification failed';
+          return;
+        }
         location.href = '/';
       } catch (err) {
         errorDiv.textContent = 'Network error';
@@ -183,6 +294,62 @@ document.addEventListener('DOMContentLoaded', () => {
 });
 
 
+// == Password Reset Forms ==
+document.addEventListener('DOMContentLoaded', () => {
+  const forgotForm = document.getElementById('forgotForm');
+  if (forgotForm) {
+    forgotForm.addEventListener('submit', async (e) => {
+      e.preventDefault();
+      const email = document.getElementById('forgotEmail').value;
+      const errorDiv = document.getElementById('forgotError');
+      const messageDiv = document.getElementById('forgotMessage');
+      errorDiv.textContent = '';
+      messageDiv.textContent = '';
+      try {
+        const res = await send('/password/forgot', {
+          method: 'POST',
+          headers: { 'Content-Type': 'application/json' },
+          body: JSON.stringify({ email })
+        });
+        const data = await res.json();
+        if (!res.ok) {
+          errorDiv.textContent = data.error || 'Request failed';
+          return;
+        }
+        messageDiv.textContent = 'If that email is registered, a reset link is on its way.';
+      } catch (err) {
+        errorDiv.textContent = 'Network error';
+      }
+    });
+  }
+
+  const resetForm = document.getElementById('resetForm');
+  if (resetForm) {
+    resetForm.addEventListener('submit', async (e) => {
+      e.preventDefault();
+      const token = new URLSearchParams(window.location.search).get('token');
+      const password = document.getElementById('resetPassword').value;
+      const errorDiv = document.getElementById('resetError');
+      errorDiv.textContent = '';
+      try {
+        const res = await send('/password/reset', {
+          method: 'POST',
+          headers: { 'Content-Type': 'application/json' },
+          body: JSON.stringify({ token, password })
+        });
+        if (!res.ok) {
+          const data = await res.json();
+          errorDiv.textContent = data.error || 'Reset failed';
+          return;
+        }
+        location.href = '/login.html';
+      } catch (err) {
+        errorDiv.textContent = 'Network error';
+      }
+    });
+  }
+});
+
 
 // == Tag functions ==
 async function fetchTags(postId = null) {
@@ -195,7 +362,7 @@ async function fetchTags(postId = null) {
 async function addTag(postId, tag) {
   if (!tag || !postId) return null;
 
-  const res = await fetch(`/posts/${postId}/tags`, {
+  const res = await send(`/posts/${postId}/tags`, {
     method: 'POST',
     headers: { 'Content-Type': 'application/json' },
     body: JSON.stringify({ tag })
@@ -208,7 +375,7 @@ async function addTag(postId, tag) {
 async function removeTag(postId, tag) {
   if (!tag || !postId) return null;
 
-  const res = await fetch(`/posts/${postId}/tags/${encodeURIComponent(tag)}`, {
+  const res = await send(`/posts/${postId}/tags/${encodeURIComponent(tag)}`, {
     method: 'DELETE'
   });
 
@@ -236,7 +403,7 @@ async function renderTags(postId, container) {
     tags.forEach(tag => {
       const tagEl = document.createElement('span');
       tagEl.className = 'tag';
-      tagEl.innerHTML = `<a href="/?tag=${encodeURIComponent(tag)}">${tag}</a>`;
+      tagEl.innerHTML = `<a href="/?tag=${encodeURIComponent(tag)}">${escapeHtml(tag)}</a>`;
 
       // Add remove button if user is logged in
       const session = fetchSession().then(user => {
@@ -288,19 +455,21 @@ async function renderTags(postId, container) {
 // Modify renderFeed to include tags
 async function renderFeed() {
   const feed = document.getElementById('feed');
+  if (!feed) return;
   feed.innerHTML = '';
 
   // Check if filtering by tag
   const urlParams = new URLSearchParams(window.location.search);
   const filterTag = urlParams.get('tag');
   let posts = [];
+  let nextCursor = '';
 
   // Show tag filter UI if we're filtering
   const tagFilterContainer = document.getElementById('tag-filter-container');
   if (filterTag) {
     tagFilterContainer.innerHTML = `
       <div class="tag-filter">
-        <div>Showing posts tagged with: <span class="tag">${filterTag}</span></div>
+        <div>Showing posts tagged with: <span class="tag">${escapeHtml(filterTag)}</span></div>
         <button id="clear-tag-filter">Clear Filter</button>
       </div>
     `;
@@ -313,71 +482,214 @@ async function renderFeed() {
   } else {
     // Clear tag filter UI
     tagFilterContainer.innerHTML = '';
-    // Regular post fetch
-    posts = await fetchPosts();
+    // Regular post fetch, one page at a time
+    const page = await fetchPosts();
+    posts = page.posts;
+    nextCursor = page.nextCursor;
   }
 
-  // Sort posts by created_at descending (reverse chronological)
-  posts.sort((a, b) => (b.created_at || 0) - (a.created_at || 0));
+  // Sort posts by publish time descending (reverse chronological)
+  posts.sort((a, b) => (b.publish_at || b.created_at || 0) - (a.publish_at || a.created_at || 0));
 
   for (const post of posts) {
-    const postDiv = document.createElement('div');
-    postDiv.className = 'post';
+    appendPost(feed, post);
+  }
+  renderLoadMore(feed, nextCursor);
+}
 
-    // Link preview HTML (unchanged from your original code)
-    let linkPreviewHtml = '';
-    if (post.link_preview && post.link_preview.url) {
-      linkPreviewHtml = `
-        <div class="link-preview">
-          ${post.link_preview.image ? `<img src="${post.link_preview.image}" alt="preview image">` : ''}
-          <div>
-            <div>${post.link_preview.title || post.link_preview.url}</div>
-            <div>${post.link_preview.description || ''}</div>
-            <a href="${post.link_preview.url}" target="_blank">${post.link_preview.url}</a>
-          </div>
-        </div>
-      `;
+// Show a button that appends the next page of posts to the feed
+function renderLoadMore(feed, cursor) {
+  if (!cursor) return;
+  const btn = document.createElement('button');
+  btn.className = 'load-more';
+  btn.textContent = 'Load more';
+  btn.onclick = async () => {
+    btn.remove();
+    const page = await fetchPosts(cursor);
+    for (const post of page.posts) {
+      appendPost(feed, post);
     }
+    renderLoadMore(feed, page.nextCursor);
+  };
+  feed.appendChild(btn);
+}
 
-    postDiv.innerHTML = `
-      <div class="post-title">${post.title} <span style='font-size:0.9em;color:#888;'>by <a href="/@${encodeURIComponent(post.author_name ? post.author_name : 'unknown')}">${post.author_name ? post.author_name : 'unknown'}</a>${post.created_at ? ' • ' + timeAgo(post.created_at) : ''}</span></div>
-      <div class="post-content">${linkify(post.content)}</div>
-      ${linkPreviewHtml}
-      <div class="tags-container" id="tags-${post.id}"></div>
-      <div class="comments" id="comments-${post.id}"></div>
-      <form class="comment-form" data-post-id="${post.id}">
-        <input type="text" placeholder="Add a comment..." required />
-        <button type="submit">Comment</button>
-      </form>
+// Label the author's posts that aren't public yet
+function statusBadge(post) {
+  const now = Date.now() / 1000;
+  if (post.status === 'draft' || post.status === 'archived') {
+    return ` <span class="status-badge">${escapeHtml(post.status)}</span>`;
+  }
+  if (post.status === 'scheduled' && post.publish_at > now) {
+    return ` <span class="status-badge">scheduled for ${new Date(post.publish_at * 1000).toLocaleString()}</span>`;
+  }
+  return '';
+}
+
+// Controls for the author to publish or schedule a post that isn't public
+// yet. Only the author is shown such posts.
+function publishControls(post) {
+  const now = Date.now() / 1000;
+  if (post.status !== 'draft' && !(post.status === 'scheduled' && post.publish_at > now)) return '';
+  return `
+    <div class="publish-controls">
+      <button type="button" class="publish-now">Publish now</button>
+      <input type="datetime-local" class="publish-at" />
+      <button type="button" class="schedule">Schedule</button>
+      <span class="error"></span>
+    </div>
+  `;
+}
+
+// Change a post's status, leaving its title and content as they are
+async function setPostStatus(post, controls, body) {
+  const res = await send(`/posts/${encodeURIComponent(post.id)}`, {
+    method: 'PUT',
+    headers: { 'Content-Type': 'application/json' },
+    body: JSON.stringify(body)
+  });
+  if (!res.ok) {
+    const data = await res.json();
+    controls.querySelector('.error').textContent = data.error || 'Update failed';
+    return;
+  }
+  renderFeed();
+}
+
+// Render a post with its tags, comments and comment form at the end of the feed
+function appendPost(feed, post) {
+  const postDiv = document.createElement('div');
+  postDiv.className = 'post';
+
+  // Link preview HTML (unchanged from your original code)
+  let linkPreviewHtml = '';
+  if (post.link_preview && post.link_preview.url) {
+    linkPreviewHtml = `
+      <div class="link-preview">
+        ${httpUrl(post.link_preview.image) ? `<img src="${escapeHtml(post.link_preview.image)}" alt="preview image">` : ''}
+        <div>
+          <div>${escapeHtml(post.link_preview.title || post.link_preview.url)}</div>
+          <div>${escapeHtml(post.link_preview.description || '')}</div>
+          <a href="${escapeHtml(httpUrl(post.link_preview.url))}" target="_blank" rel="noopener noreferrer">${escapeHtml(post.link_preview.url)}</a>
+        </div>
+      </div>
     `;
+  }
 
-    feed.appendChild(postDiv);
+  postDiv.innerHTML = `
+    <div class="post-title">${escapeHtml(post.title || '')}${statusBadge(post)} <span style='font-size:0.9em;color:#888;'>by ${authorLink(post)}${post.created_at ? ' • ' + timeAgo(post.created_at) : ''}</span></div>
+    ${publishControls(post)}
+    <div class="post-content">${contentHtml(post)}</div>
+    ${linkPreviewHtml}
+    <div class="tags-container" id="tags-${post.id}"></div>
+    <div class="comments" id="comments-${post.id}"></div>
+    <form class="comment-form" data-post-id="${post.id}">
+      <input type="text" placeholder="Add a comment..." required />
+      <button type="submit">Comment</button>
+    </form>
+  `;
+
+  feed.appendChild(postDiv);
+
+  const controls = postDiv.querySelector('.publish-controls');
+  if (controls) {
+    controls.querySelector('.publish-now').onclick = () => setPostStatus(post, controls, { status: 'published' });
+    controls.querySelector('.schedule').onclick = () => {
+      const publishAt = Math.floor(new Date(controls.querySelector('.publish-at').value).getTime() / 1000) || 0;
+      setPostStatus(post, controls, { status: 'scheduled', publish_at: publishAt });
+    };
+  }
 
-    // Render tags for this post
-    renderTags(post.id, document.getElementById(`tags-${post.id}`));
+  // Render tags for this post
+  renderTags(post.id, document.getElementById(`tags-${post.id}`));
 
-    // Render comments (unchanged from your original code)
-    renderComments(post.id);
+  // Render comments (unchanged from your original code)
+  renderComments(post.id);
 
-    // Add comment form event listener (unchanged from your original code)
-    postDiv.querySelector('.comment-form').addEventListener('submit', async (e) => {
-      e.preventDefault();
-      const input = e.target.querySelector('input');
-      const content = input.value;
-      await fetch('/comments', {
-        method: 'POST',
-        headers: { 'Content-Type': 'application/json' },
-        body: JSON.stringify({ content, post_id: post.id })
-      });
-      input.value = '';
-      renderComments(post.id);
+  // Add comment form event listener (unchanged from your original code)
+  postDiv.querySelector('.comment-form').addEventListener('submit', async (e) => {
+    e.preventDefault();
+    const input = e.target.querySelector('input');
+    const content = input.value;
+    await send('/comments', {
+      method: 'POST',
+      headers: { 'Content-Type': 'application/json' },
+      body: JSON.stringify({ content, post_id: post.id })
     });
+    input.value = '';
+    renderComments(post.id);
+  });
+}
+
+// Link an author's name to their profile. Posts and comments made before
+// handles existed fall back to the author's name.
+function authorLink(item) {
+  const name = item.author_name || 'unknown';
+  const handle = item.author_handle || name;
+  return `<a href="/@${encodeURIComponent(handle)}">${escapeHtml(name)}</a>`;
+}
+
+// Show a follow or unfollow button for another user when logged in
+async function renderFollowButton(userId) {
+  const container = document.getElementById('follow-container');
+  const me = await fetchSession();
+  if (!me || me.id === userId) return;
+  const res = await fetch('/users/me/following');
+  if (!res.ok) return;
+  const following = ((await res.json()).user_ids || []).includes(userId);
+  container.innerHTML = `<button id="followBtn">${following ? 'Unfollow' : 'Follow'}</button>`;
+  document.getElementById('followBtn').onclick = async () => {
+    await send(`/users/${encodeURIComponent(userId)}/follow`, { method: following ? 'DELETE' : 'POST' });
+    renderProfile();
+  };
+}
+
+// Render the public profile at /@:handle with the user's posts and comments
+async function renderProfile() {
+  const handle = decodeURIComponent(window.location.pathname.replace(/^\/@/, ''));
+  const title = document.getElementById('profile-title');
+  const res = await fetch(`/users/by-handle/${encodeURIComponent(handle)}`);
+  if (!res.ok) {
+    title.textContent = 'User not found';
+    return;
   }
+  const data = await res.json();
+  const p = data.profile;
+  document.title = `${p.display_name || p.name} - Micro Blog`;
+  title.textContent = p.display_name || p.name;
+
+  const header = document.getElementById('profile-header');
+  header.innerHTML = `
+    ${p.avatar_url ? `<img class="avatar" src="${escapeHtml(p.avatar_url)}" alt="">` : ''}
+    <div>@${escapeHtml(p.handle)}${p.created_at ? ' • joined ' + timeAgo(p.created_at) : ''}</div>
+    ${p.bio ? `<div class="bio">${linkify(escapeHtml(p.bio))}</div>` : ''}
+    ${p.website ? `<div><a href="${escapeHtml(p.website)}" target="_blank" rel="nofollow noopener">${escapeHtml(p.website)}</a></div>` : ''}
+    <div>${data.post_count || 0} posts • ${data.comment_count || 0} comments • ${data.follower_count || 0} followers • ${data.following_count || 0} following</div>
+    <div id="follow-container"></div>
+  `;
+  renderFollowButton(p.id);
+
+  const [postsRes, commentsRes] = await Promise.all([
+    fetch(`/posts?author_id=${encodeURIComponent(p.id)}`),
+    fetch(`/comments?author_id=${encodeURIComponent(p.id)}`)
+  ]);
+  const posts = (await postsRes.json()).posts || [];
+  const comments = (await commentsRes.json()).comments || [];
+  document.getElementById('user-posts').innerHTML = posts.length
+    ? posts.map(post => `<div class="post"><div class="post-title">${escapeHtml(post.title || '')}${post.created_at ? ` <span style='font-size:0.9em;color:#888;'>${timeAgo(post.created_at)}</span>` : ''}</div><div class="post-content">${contentHtml(post)}</div></div>`).join('')
+    : '<em>No posts yet.</em>';
+  document.getElementById('user-comments').innerHTML = comments.length
+    ? comments.map(c => `<div class="comment">${contentHtml(c)}${c.created_at ? ` <span style='color:#888;'>${timeAgo(c.created_at)}</span>` : ''}</div>`).join('')
+    : '<em>No comments yet.</em>';
 }
 
 // Update your document.ready code to check for tag parameter
 document.addEventListener('DOMContentLoaded', async () => {
   const user = await fetchSession();
   renderAuthLinks(user);
+  if (document.getElementById('profile-title')) {
+    renderProfile();
+    return;
+  }
   renderFeed();
 });
\ No newline at end of file
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go-micro.dev/v5/errors"
+	"go-micro.dev/v5/store"
+
+	"github.com/micro/blog/web/lockout"
+)
+
+// newLimiter sets the policies for failed logins and signups:
+//
+//   - account: a login email, or a user entering their password or a 2FA code
+//   - ip:      every failed login from an address
+//   - signup:  every signup from an address, since each one costs a password hash
+//   - mail:    every reset or verification mail asked for to an email address
+//   - mailip:  every such request from an address, whatever it was sent to
+func newLimiter() *lockout.Limiter {
+	return lockout.New(store.DefaultStore, map[string]lockout.Policy{
+		"account": {Free: 5, Base: 30 * time.Second, Max: time.Hour, Window: 24 * time.Hour},
+		"ip":      {Free: 20, Base: 30 * time.Second, Max: time.Hour, Window: 24 * time.Hour},
+		"signup":  {Free: 10, Base: time.Minute, Max: time.Hour, Window: 24 * time.Hour},
+		"mail":    {Free: 3, Base: 5 * time.Minute, Max: 24 * time.Hour, Window: 24 * time.Hour},
+		"mailip":  {Free: 20, Base: 5 * time.Minute, Max: 24 * time.Hour, Window: 24 * time.Hour},
+	})
+}
+
+// throttled reserves an attempt against keys. If any of them is locked it
+// responds 429 with Retry-After and returns true. The attempt counts as a
+// failure until settle takes it back.
+func throttled(c *gin.Context, limiter *lockout.Limiter, keys ...string) bool {
+	wait := limiter.Attempt(keys...)
+	if wait <= 0 {
+		return false
+	}
+	secs := int(math.Ceil(wait.Seconds()))
+	c.Header("Retry-After", strconv.Itoa(secs))
+	c.JSON(http.StatusTooManyRequests, gin.H{"error": "too many attempts, try again later", "retry_after": secs})
+	return true
+}
+
+// settle ends an attempt throttled reserved against an account and the
+// client's address, given the error it ended with. A wrong password or code
+// (Unauthorized) stays counted against both and settle returns true. Success
+// resets the account and takes the attempt back from the address, whose
+// other failures are kept. Any other error takes it back from both.
+func settle(limiter *lockout.Limiter, err error, accountKey, ipKey string) bool {
+	switch {
+	case err == nil:
+		limiter.Reset(accountKey)
+		limiter.Forgive(ipKey)
+	case errors.FromError(err).Code == http.StatusUnauthorized:
+		return true
+	default:
+		limiter.Forgive(accountKey, ipKey)
+	}
+	return false
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"github.com/gin-gonic/gin"
+
+	userProto "github.com/micro/blog/users/proto"
+)
+
+// publicUser is the part of a user record anyone may see. The email,
+// roles and account settings are only shown to the user and to admins.
+func publicUser(u *userProto.User) gin.H {
+	if u == nil {
+		return nil
+	}
+	return gin.H{
+		"id":           u.Id,
+		"handle":       u.Handle,
+		"name":         u.Name,
+		"display_name": u.DisplayName,
+		"bio":          u.Bio,
+		"avatar_url":   u.AvatarUrl,
+		"website":      u.Website,
+		"created_at":   u.CreatedAt,
+	}
+}
+
+// seesFullUser reports whether the current user may see all of u's record:
+// their own, or anyone's if they administer users
+func seesFullUser(c *gin.Context, users userProto.UsersService, u *userProto.User) bool {
+	if userID, _ := c.Get("user_id"); u != nil && userID == u.Id {
+		return true
+	}
+	return hasPermission(c, users, "users:admin")
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go-micro.dev/v5/client"
+
+	userProto "github.com/micro/blog/users/proto"
+)
+
+// roleUsers reads users with the given roles
+type roleUsers struct {
+	userProto.UsersService
+	roles map[string][]string
+}
+
+func (u roleUsers) Read(ctx context.Context, in *userProto.ReadRequest, opts ...client.CallOption) (*userProto.ReadResponse, error) {
+	return &userProto.ReadResponse{User: &userProto.User{Id: in.Id, Roles: u.roles[in.Id]}}, nil
+}
+
+func TestPublicUser(t *testing.T) {
+	u := &userProto.User{Id: "jane", Handle: "jane", Name: "Jane Doe", Email: "jane@example.com", Roles: []string{"admin"}, TwoFactor: true}
+	b, err := json.Marshal(publicUser(u))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(b, &fields); err != nil {
+		t.Fatal(err)
+	}
+	for _, private := range []string{"email", "roles", "two_factor", "verified"} {
+		if _, ok := fields[private]; ok {
+			t.Errorf("public user has %s: %s", private, b)
+		}
+	}
+	if fields["handle"] != "jane" || fields["name"] != "Jane Doe" {
+		t.Errorf("public user = %s, want the handle and name", b)
+	}
+}
+
+func TestSeesFullUser(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	users := roleUsers{roles: map[string][]string{"root": {"admin"}, "ed": {"editor"}}}
+	jane := &userProto.User{Id: "jane"}
+
+	tests := []struct {
+		caller string
+		want   bool
+	}{
+		{"", false},
+		{"john", false},
+		{"ed", false},
+		{"jane", true},
+		{"root", true},
+	}
+	for _, tt := range tests {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		if tt.caller != "" {
+			c.Set("user_id", tt.caller)
+		}
+		if got := seesFullUser(c, users, jane); got != tt.want {
+			t.Errorf("seesFullUser as %q = %v, want %v", tt.caller, got, tt.want)
+		}
+	}
+}